@@ -0,0 +1,66 @@
+package envscan
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+// ErrRequired is an error when a required environment variable is not set.
+var ErrRequired = errors.New("required environment variable is not set")
+
+type decoder struct {
+	parser
+	c *config
+}
+
+func (d *decoder) ExtractValue(tag any) (any, error) {
+	t := tag.(*envTag)
+	name := d.c.prefix + t.name
+	raw, ok := d.c.lookup(name)
+	if !ok {
+		if file, hasFile := d.c.lookup(name + "_FILE"); hasFile {
+			b, err := d.c.readFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("can't read %s_FILE for field '%s': %w", name, t.path, err)
+			}
+			raw = strings.TrimRight(string(b), "\r\n")
+			ok = true
+		}
+	}
+	if !ok {
+		if t.hasDefault {
+			v, err := convert(t.def, t)
+			if err != nil {
+				return nil, err
+			}
+			return runtimescan.Default(v), nil
+		}
+		if t.required {
+			return nil, fmt.Errorf("%s (field '%s'): %w", name, t.path, ErrRequired)
+		}
+		return nil, runtimescan.Skip
+	}
+	return convert(raw, t)
+}
+
+// Decode reads environment variables into dest. dest should be a pointer of struct.
+//
+// If NAME is not set, the content of the file that NAME_FILE points is used instead (trailing newlines are trimmed).
+// It is a common convention to pass secrets via Docker or Kubernetes.
+// If neither is set, the default value is used. Missing required variables are reported
+// as ErrRequired and all errors are aggregated into runtimescan.Errors.
+func Decode(dest any, opts ...Option) error {
+	if !runtimescan.IsPointerOfStruct(dest) {
+		return errors.New("dest should be *struct")
+	}
+	c := newConfig(opts)
+	d := &decoder{
+		parser: newParser(reflect.TypeOf(dest).Elem()),
+		c:      c,
+	}
+	return runtimescan.Decode(dest, tags, d)
+}
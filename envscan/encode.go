@@ -0,0 +1,115 @@
+package envscan
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/future-architect/tagscanner/internal/configscan"
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+type encoder struct {
+	parser
+	configscan.ChildVisitor
+	c *config
+	w io.Writer
+}
+
+func (e *encoder) VisitField(tag, value any) error {
+	t := tag.(*envTag)
+	var lines []string
+	if t.doc != "" {
+		lines = append(lines, "# "+t.doc)
+	}
+	info := []string{t.elemType.String()}
+	if t.required {
+		info = append(info, "required")
+	}
+	if t.hasDefault {
+		info = append(info, "default: "+t.def)
+	}
+	lines = append(lines, "# "+strings.Join(info, ", "))
+	str, err := format(value, t)
+	if err != nil {
+		return err
+	}
+	if str == "" && t.hasDefault {
+		str = t.def
+	}
+	lines = append(lines, e.c.prefix+t.name+"="+quote(str))
+	_, err = fmt.Fprintf(e.w, "%s\n\n", strings.Join(lines, "\n"))
+	return err
+}
+
+// format converts the field value into the string of environment variable.
+// Zero values are rendered as an empty string.
+func format(value any, t *envTag) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	v := reflect.ValueOf(value)
+	if v.IsZero() {
+		return "", nil
+	}
+	switch vv := value.(type) {
+	case time.Duration:
+		return vv.String(), nil
+	case encoding.TextMarshaler:
+		b, err := vv.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("field '%s': %w", t.path, err)
+		}
+		return string(b), nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := format(v.Index(i).Interface(), t)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, t.sep), nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// quote quotes the value if it contains characters that .env parsers treat specially.
+func quote(s string) string {
+	if strings.ContainsAny(s, " \t\r\n#\"'\\$`") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Encode writes a documented .env template of src into w. src should be a struct or a pointer of struct.
+//
+// Each variable has comments of its doc tag, type, required flag and default value. The current field value
+// is used as the value, and the default value is used for zero values. Fields in nil embedded pointers
+// are rendered as zero values.
+func Encode(w io.Writer, src any, opts ...Option) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() != reflect.Pointer {
+		p := reflect.New(sv.Type())
+		p.Elem().Set(sv)
+		sv = p
+	}
+	if !runtimescan.IsPointerOfStruct(sv.Interface()) {
+		return errors.New("src should be struct or *struct")
+	}
+	c := newConfig(opts)
+	e := &encoder{
+		parser: newParser(sv.Type().Elem()),
+		c:      c,
+		w:      w,
+	}
+	// variables of fields in nil embedded pointers are rendered from zero values
+	return runtimescan.Encode(configscan.AllocEmbedded(sv.Interface()), tags, e)
+}
@@ -0,0 +1,182 @@
+// Package envscan binds environment variables to structs by using runtimescan.
+//
+//	type Config struct {
+//		Port  int           `env:"PORT,default=8080" doc:"listen port"`
+//		Hosts []string      `env:"HOSTS,sep=;"`
+//		DB    struct {
+//			Host     string `env:",required"` // DB_HOST
+//			Password string `env:",required"` // DB_PASSWORD or the content of DB_PASSWORD_FILE
+//		}
+//	}
+//
+// Fields without name use SCREAMING_SNAKE_CASE of the field name. Child structs add their name as a prefix
+// (DB_HOST for DB.Host) and embedded structs don't. Child structs should be values. Pointers of struct
+// that aren't embedded are reported as runtimescan.ErrParseTag.
+package envscan
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/future-architect/tagscanner/internal/configscan"
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+const (
+	// TagKey is a tag key of envscan.
+	TagKey = "env"
+	// DocTagKey is a tag key of documents that Encode() writes into the template.
+	DocTagKey = "doc"
+)
+
+var tags = []string{TagKey, DocTagKey}
+
+var schema = runtimescan.OptionSchema{
+	Options: []runtimescan.OptionSpec{
+		{Key: "required", Type: runtimescan.FlagOption, Conflicts: []string{"default"}},
+		{Key: "default", Type: runtimescan.StringOption},
+		{Key: "sep", Type: runtimescan.StringOption},
+	},
+}
+
+// Option is an optional setting of Decode() and Encode().
+type Option func(c *config)
+
+type config struct {
+	prefix   string
+	lookup   func(key string) (string, bool)
+	readFile func(name string) ([]byte, error)
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		lookup:   os.LookupEnv,
+		readFile: os.ReadFile,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithPrefix adds prefix to all environment variable names like "APP_".
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// WithLookup replaces os.LookupEnv. It is useful for tests.
+func WithLookup(lookup func(key string) (string, bool)) Option {
+	return func(c *config) {
+		c.lookup = lookup
+	}
+}
+
+// WithReadFile replaces os.ReadFile that is used for NAME_FILE variables. It is useful for tests.
+func WithReadFile(readFile func(name string) ([]byte, error)) Option {
+	return func(c *config) {
+		c.readFile = readFile
+	}
+}
+
+type envTag struct {
+	name       string
+	path       string
+	required   bool
+	def        string
+	hasDefault bool
+	sep        string
+	doc        string
+	elemType   reflect.Type
+}
+
+// parser computes environment variable names. Names of child structs are kept to make prefixes.
+type parser struct {
+	prefixes configscan.Prefixes
+}
+
+func newParser(root reflect.Type) parser {
+	return parser{prefixes: configscan.NewPrefixes(root)}
+}
+
+func (p parser) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	return p.ParseTags(name, runtimescan.TagValues{{Key: tagKey, Value: tagStr}}, pathStr, elemType)
+}
+
+func (p parser) ParseTags(name string, tagValues runtimescan.TagValues, pathStr string, elemType reflect.Type) (any, error) {
+	envStr := tagValues.Get(TagKey)
+	if envStr == "-" {
+		return nil, runtimescan.Skip
+	}
+	o, err := schema.Parse(envStr, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	envName := o.Name
+	if envName == "" {
+		envName = runtimescan.ScreamingSnakeCase.Convert(name)
+	}
+	if configscan.IsSection(elemType) {
+		if err := p.prefixes.Enter(name, pathStr, envName, o.Name != "", "_", elemType); err != nil {
+			return nil, err
+		}
+		return nil, runtimescan.Skip
+	}
+	def, hasDefault := o.Lookup("default")
+	sep := ","
+	if s, ok := o.Lookup("sep"); ok {
+		sep = s.Value
+	}
+	tag := &envTag{
+		name:       p.prefixes.Name(pathStr, envName),
+		path:       pathStr,
+		required:   o.Has("required"),
+		def:        def.Value,
+		hasDefault: hasDefault,
+		sep:        sep,
+		doc:        tagValues.Get(DocTagKey),
+		elemType:   elemType,
+	}
+	if elemType.Kind() == reflect.Struct {
+		return tag, runtimescan.SkipTraverse
+	}
+	return tag, nil
+}
+
+// convert converts the string into the value of the field type.
+func convert(raw string, t *envTag) (any, error) {
+	return convertType(raw, t.elemType, t)
+}
+
+func convertType(raw string, et reflect.Type, t *envTag) (any, error) {
+	if et.Kind() == reflect.Slice && et.Elem().Kind() != reflect.Uint8 && !configscan.IsText(et) {
+		result := reflect.MakeSlice(et, 0, 0)
+		if raw == "" {
+			return result.Interface(), nil
+		}
+		for _, s := range strings.Split(raw, t.sep) {
+			v, err := convertType(strings.TrimSpace(s), et.Elem(), t)
+			if err != nil {
+				return nil, err
+			}
+			ev := reflect.New(et.Elem()).Elem()
+			if err := runtimescan.FuzzyAssign(ev, v); err != nil {
+				return nil, fmt.Errorf("field '%s': %w", t.path, err)
+			}
+			result = reflect.Append(result, ev)
+		}
+		return result.Interface(), nil
+	}
+	if !configscan.IsSupported(et) {
+		// runtimescan.FuzzyAssign() converts it
+		return raw, nil
+	}
+	v, err := configscan.Parse(raw, et)
+	if err != nil {
+		return nil, fmt.Errorf("field '%s': %w", t.path, err)
+	}
+	return v, nil
+}
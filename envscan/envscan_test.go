@@ -0,0 +1,229 @@
+package envscan
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+	"github.com/stretchr/testify/assert"
+)
+
+type Common struct {
+	LogLevel string `env:",default=info"`
+}
+
+type DB struct {
+	Host     string `env:",required" doc:"database host"`
+	Port     int    `env:",default=5432"`
+	Password string
+}
+
+type Config struct {
+	Common
+	Port    int           `env:"PORT,default=8080" doc:"listen port"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:",default=5s"`
+	Hosts   []string      `env:",sep=;"`
+	Ports   []int
+	Started time.Time
+	DB      DB
+	Replica DB     `env:"RO"`
+	Secret  string `env:"-"`
+}
+
+func lookup(env map[string]string) Option {
+	return WithLookup(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+}
+
+func TestDecode(t *testing.T) {
+	var c Config
+	err := Decode(&c, lookup(map[string]string{
+		"DEBUG":            "true",
+		"HOSTS":            "a.example.com; b.example.com",
+		"PORTS":            "1,2,3",
+		"STARTED":          "2020-01-02T03:04:05Z",
+		"DB_HOST":          "db.example.com",
+		"DB_PASSWORD":      "pass",
+		"RO_HOST":          "ro.example.com",
+		"RO_PORT":          "15432",
+		"SECRET":           "secret",
+		"COMMON_LOG_LEVEL": "debug",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "info", c.LogLevel)
+	assert.Equal(t, 8080, c.Port)
+	assert.True(t, c.Debug)
+	assert.Equal(t, 5*time.Second, c.Timeout)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, c.Hosts)
+	assert.Equal(t, []int{1, 2, 3}, c.Ports)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), c.Started)
+	assert.Equal(t, DB{Host: "db.example.com", Port: 5432, Password: "pass"}, c.DB)
+	assert.Equal(t, DB{Host: "ro.example.com", Port: 15432}, c.Replica)
+	assert.Equal(t, "", c.Secret)
+}
+
+func TestDecode_Prefix(t *testing.T) {
+	var c DB
+	err := Decode(&c, WithPrefix("APP_"), lookup(map[string]string{
+		"APP_HOST": "localhost",
+		"HOST":     "ignored",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", c.Host)
+}
+
+func TestDecode_File(t *testing.T) {
+	var c DB
+	err := Decode(&c,
+		lookup(map[string]string{
+			"HOST":          "localhost",
+			"PASSWORD_FILE": "/run/secrets/password",
+		}),
+		WithReadFile(func(name string) ([]byte, error) {
+			if name == "/run/secrets/password" {
+				return []byte("secret\n"), nil
+			}
+			return nil, os.ErrNotExist
+		}))
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", c.Password)
+
+	err = Decode(&c,
+		lookup(map[string]string{
+			"HOST":          "localhost",
+			"PASSWORD_FILE": "/not/found",
+		}),
+		WithReadFile(func(name string) ([]byte, error) {
+			return nil, os.ErrNotExist
+		}))
+	var errs *runtimescan.Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], os.ErrNotExist)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var c Config
+	err := Decode(&c, lookup(map[string]string{
+		"PORT":    "http",
+		"RO_HOST": "ro.example.com",
+	}))
+	var errs *runtimescan.Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Len(t, errs.Errors, 2)
+		assert.Contains(t, errs.Errors[0].Error(), "Port")
+		assert.ErrorIs(t, errs.Errors[1], ErrRequired)
+		assert.Contains(t, errs.Errors[1].Error(), "DB_HOST")
+	}
+}
+
+func TestDecode_InvalidTag(t *testing.T) {
+	var c struct {
+		Host string `env:",required,default=localhost"`
+	}
+	err := Decode(&c, lookup(map[string]string{}))
+	var errs *runtimescan.Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], runtimescan.ErrParseTag)
+	}
+
+	var p struct {
+		DB *DB
+	}
+	err = Decode(&p, lookup(map[string]string{"DB_HOST": "localhost"}))
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], runtimescan.ErrParseTag)
+		assert.Contains(t, errs.Errors[0].Error(), "use envscan.DB instead of *envscan.DB")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	c := Config{
+		Port:  80,
+		Hosts: []string{"a", "b"},
+		DB:    DB{Host: "localhost", Password: "p@ss word"},
+	}
+	var b strings.Builder
+	err := Encode(&b, c, WithPrefix("APP_"))
+	assert.NoError(t, err)
+	assert.Equal(t, `# string, default: info
+APP_LOG_LEVEL=info
+
+# listen port
+# int, default: 8080
+APP_PORT=80
+
+# bool
+APP_DEBUG=
+
+# time.Duration, default: 5s
+APP_TIMEOUT=5s
+
+# []string
+APP_HOSTS=a;b
+
+# []int
+APP_PORTS=
+
+# time.Time
+APP_STARTED=
+
+# database host
+# string, required
+APP_DB_HOST=localhost
+
+# int, default: 5432
+APP_DB_PORT=5432
+
+# string
+APP_DB_PASSWORD="p@ss word"
+
+# database host
+# string, required
+APP_RO_HOST=
+
+# int, default: 5432
+APP_RO_PORT=5432
+
+# string
+APP_RO_PASSWORD=
+
+`, b.String())
+}
+
+func TestEncode_NilEmbeddedPointer(t *testing.T) {
+	type Cfg struct {
+		*DB
+		Debug bool
+	}
+	c := Cfg{}
+	var b strings.Builder
+	err := Encode(&b, &c)
+	assert.NoError(t, err)
+	assert.Nil(t, c.DB)
+	assert.Equal(t, `# database host
+# string, required
+HOST=
+
+# int, default: 5432
+PORT=5432
+
+# string
+PASSWORD=
+
+# bool
+DEBUG=
+
+`, b.String())
+
+	var d Cfg
+	err = Decode(&d, lookup(map[string]string{"HOST": "localhost"}))
+	assert.NoError(t, err)
+	assert.Equal(t, &DB{Host: "localhost", Port: 5432}, d.DB)
+}
@@ -0,0 +1,36 @@
+package envscan_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/future-architect/tagscanner/envscan"
+)
+
+func ExampleDecode() {
+	type Config struct {
+		Port int `env:"PORT,default=8080"`
+		DB   struct {
+			Host string `env:",required"`
+		}
+	}
+	env := map[string]string{"DB_HOST": "localhost"}
+	var c Config
+	_ = envscan.Decode(&c, envscan.WithLookup(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}))
+	fmt.Printf("%+v\n", c)
+	// Output: {Port:8080 DB:{Host:localhost}}
+}
+
+func ExampleEncode() {
+	type Config struct {
+		Port int `env:"PORT,default=8080" doc:"listen port"`
+	}
+	_ = envscan.Encode(os.Stdout, Config{})
+	// Output:
+	// # listen port
+	// # int, default: 8080
+	// PORT=8080
+}
@@ -5,7 +5,6 @@ import (
 	"math"
 	"reflect"
 	"strings"
-	"unicode"
 
 	"github.com/future-architect/tagscanner/runtimescan"
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -36,46 +35,17 @@ const (
 func (t defaultFieldType) Convert(source string) string {
 	switch t {
 	case lowerCase:
-		return strings.ToLower(source)
+		return runtimescan.LowerCase.Convert(source)
 	case hyphenatedLowerCase:
-		words := splitWord(source)
-		var result []string
-		for _, word := range words {
-			result = append(result, strings.ToLower(word))
-		}
-		return strings.Join(result, "-")
+		return runtimescan.KebabCase.Convert(source)
 	case hyphenatedPascalCase:
-		words := splitWord(source)
-		var result []string
-		for _, word := range words {
-			result = append(result, strings.Title(word))
-		}
-		return strings.Join(result, "-")
+		return runtimescan.TrainCase.Convert(source)
 	case noField:
 		return ""
 	}
 	return ""
 }
 
-func splitWord(source string) []string {
-	var word []rune
-	var result []string
-	for _, c := range source {
-		if unicode.IsUpper(c) {
-			if len(word) != 0 {
-				result = append(result, string(word))
-			}
-			word = []rune{c}
-		} else {
-			word = append(word, c)
-		}
-	}
-	if len(word) > 0 {
-		result = append(result, string(word))
-	}
-	return result
-}
-
 var fieldTypes = map[FieldType]struct {
 	Type     defaultFieldType
 	Optional bool
@@ -34,6 +34,18 @@ func Test_defaultFieldType_Convert(t *testing.T) {
 			arg:  "AbcDef",
 			want: "Abc-Def",
 		},
+		{
+			name: "hyphenated-lower-case with acronym",
+			t:    hyphenatedLowerCase,
+			arg:  "UserID",
+			want: "user-id",
+		},
+		{
+			name: "hyphenated-pascal-case with acronym",
+			t:    hyphenatedPascalCase,
+			arg:  "XRequestID",
+			want: "X-Request-Id",
+		},
 		{
 			name: "no field",
 			t:    noField,
@@ -0,0 +1,24 @@
+package flagscan_test
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/future-architect/tagscanner/flagscan"
+)
+
+func ExampleRegister() {
+	type Config struct {
+		Port int      `flag:"port,usage=listen port"`
+		Tags []string `flag:"tag"`
+		DB   struct {
+			Host string
+		}
+	}
+	c := Config{Port: 8080}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	_ = flagscan.Register(fs, &c)
+	_ = fs.Parse([]string{"-tag=a", "-tag=b", "-db.host=localhost"})
+	fmt.Printf("%+v\n", c)
+	// Output: {Port:8080 Tags:[a b] DB:{Host:localhost}}
+}
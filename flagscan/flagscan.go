@@ -0,0 +1,141 @@
+// Package flagscan defines command line flags of flag.FlagSet from struct fields by using runtimescan.
+//
+//	type Config struct {
+//		Port    int      `flag:"port,usage=listen port"`
+//		Verbose bool     `flag:"v"`
+//		Tags    []string `flag:"tag,usage='repeatable, like -tag=a -tag=b'"`
+//		DB      struct {
+//			Host string // -db.host
+//		}
+//	}
+//
+//	config := Config{Port: 8080}
+//	fs := flag.NewFlagSet("app", flag.ExitOnError)
+//	flagscan.Register(fs, &config)
+//	fs.Parse(os.Args[1:]) // parsed values are written into config
+//
+// Fields without name use kebab-case of the field name. Child structs add their name as a dotted prefix
+// (db.host for DB.Host) and embedded structs don't. Child structs should be values. Pointers of struct
+// that aren't embedded are reported as runtimescan.ErrParseTag.
+package flagscan
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/future-architect/tagscanner/internal/configscan"
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+// TagKey is a tag key of flagscan.
+const TagKey = "flag"
+
+var tags = []string{TagKey}
+
+var schema = runtimescan.OptionSchema{
+	Options: []runtimescan.OptionSpec{
+		{Key: "usage", Type: runtimescan.StringOption},
+	},
+}
+
+type flagTag struct {
+	name     string
+	path     string
+	usage    string
+	elemType reflect.Type
+}
+
+// parser computes flag names. Names of child structs are kept to make prefixes.
+type parser struct {
+	prefixes configscan.Prefixes
+}
+
+func newParser(root reflect.Type) parser {
+	return parser{prefixes: configscan.NewPrefixes(root)}
+}
+
+func (p parser) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	if tagStr == "-" {
+		return nil, runtimescan.Skip
+	}
+	o, err := schema.Parse(tagStr, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	flagName := o.Name
+	if flagName == "" {
+		flagName = runtimescan.KebabCase.Convert(name)
+	}
+	if configscan.IsSection(elemType) {
+		if err := p.prefixes.Enter(name, pathStr, flagName, o.Name != "", ".", elemType); err != nil {
+			return nil, err
+		}
+		return nil, runtimescan.Skip
+	}
+	t := elemType
+	if t.Kind() == reflect.Slice && !configscan.IsText(t) {
+		t = t.Elem()
+	}
+	if !configscan.IsSupported(t) {
+		return nil, fmt.Errorf("field '%s' has unsupported type %s: %w", pathStr, elemType, runtimescan.ErrParseTag)
+	}
+	tag := &flagTag{
+		name:     p.prefixes.Name(pathStr, flagName),
+		path:     pathStr,
+		usage:    o.Get("usage"),
+		elemType: elemType,
+	}
+	if elemType.Kind() == reflect.Struct {
+		return tag, runtimescan.SkipTraverse
+	}
+	return tag, nil
+}
+
+// register defines flags with the current field values while runtimescan visits fields.
+type register struct {
+	parser
+	configscan.ChildVisitor
+	fs   *flag.FlagSet
+	dest any
+}
+
+func (r *register) VisitField(tag, value any) error {
+	t := tag.(*flagTag)
+	if r.fs.Lookup(t.name) != nil {
+		// fs.Var() panics
+		return fmt.Errorf("flag '%s' of field '%s' is already defined: %w", t.name, t.path, runtimescan.ErrParseTag)
+	}
+	v := &fieldValue{
+		dest:  r.dest,
+		tag:   t,
+		value: value,
+	}
+	if v.isBool() {
+		r.fs.Var(&boolValue{v}, t.name, t.usage)
+	} else {
+		r.fs.Var(v, t.name, t.usage)
+	}
+	return nil
+}
+
+// Register defines flags of fs from fields of dest. dest should be a pointer of struct.
+//
+// The current field values are used as default values and fs.Parse() writes parsed values back into dest.
+// Slice fields are repeatable flags: the first occurrence replaces the current value and the rest are appended.
+// Each value can also contain several comma separated items. A flag name that is already defined in fs
+// (for example, by fields of two embedded structs) is reported as runtimescan.ErrParseTag instead of panicking.
+// Fields in nil embedded pointers have flags too, and the pointers are allocated when their flags are parsed.
+func Register(fs *flag.FlagSet, dest any) error {
+	if !runtimescan.IsPointerOfStruct(dest) {
+		return errors.New("dest should be *struct")
+	}
+	r := &register{
+		parser: newParser(reflect.TypeOf(dest).Elem()),
+		fs:     fs,
+		dest:   dest,
+	}
+	// flags of fields in nil embedded pointers are defined too. runtimescan.Set() allocates them when parsed.
+	return runtimescan.Encode(configscan.AllocEmbedded(dest), tags, r)
+}
@@ -0,0 +1,164 @@
+package flagscan
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+	"github.com/stretchr/testify/assert"
+)
+
+type Common struct {
+	Verbose bool `flag:"v,usage=verbose output"`
+}
+
+type DB struct {
+	Host string `flag:"host,usage=database host"`
+	Port int
+}
+
+type Config struct {
+	Common
+	Port     int           `flag:"port,usage=listen port"`
+	Timeout  time.Duration `flag:"timeout"`
+	Ratio    *float64
+	Tags     []string `flag:"tag,usage='repeatable, like -tag=a -tag=b'"`
+	IDs      []int    `flag:"id"`
+	IP       net.IP
+	DB       DB
+	Internal string `flag:"-"`
+}
+
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func TestRegister(t *testing.T) {
+	c := Config{
+		Port:    8080,
+		Timeout: time.Second,
+		Tags:    []string{"default"},
+		DB:      DB{Host: "localhost", Port: 5432},
+	}
+	fs := newFlagSet()
+	err := Register(fs, &c)
+	assert.NoError(t, err)
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	assert.Equal(t, []string{"db.host", "db.port", "id", "ip", "port", "ratio", "tag", "timeout", "v"}, names)
+	assert.Equal(t, "8080", fs.Lookup("port").DefValue)
+	assert.Equal(t, "listen port", fs.Lookup("port").Usage)
+	assert.Equal(t, "1s", fs.Lookup("timeout").DefValue)
+	assert.Equal(t, "default", fs.Lookup("tag").DefValue)
+	assert.Equal(t, "repeatable, like -tag=a -tag=b", fs.Lookup("tag").Usage)
+	assert.Equal(t, "localhost", fs.Lookup("db.host").DefValue)
+
+	err = fs.Parse([]string{
+		"-v", "-port=80", "-timeout", "1m", "-ratio=0.5",
+		"-tag=a", "-tag", "b,c", "-id=1", "-id=2",
+		"-ip=127.0.0.1", "-db.host=db.example.com", "rest",
+	})
+	assert.NoError(t, err)
+	assert.True(t, c.Verbose)
+	assert.Equal(t, 80, c.Port)
+	assert.Equal(t, time.Minute, c.Timeout)
+	if assert.NotNil(t, c.Ratio) {
+		assert.Equal(t, 0.5, *c.Ratio)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, c.Tags)
+	assert.Equal(t, []int{1, 2}, c.IDs)
+	assert.Equal(t, net.ParseIP("127.0.0.1"), c.IP)
+	assert.Equal(t, DB{Host: "db.example.com", Port: 5432}, c.DB)
+	assert.Equal(t, []string{"rest"}, fs.Args())
+	assert.Equal(t, 80, fs.Lookup("port").Value.(flag.Getter).Get())
+}
+
+func TestRegister_NilEmbeddedPointer(t *testing.T) {
+	type Server struct {
+		Host string
+	}
+	type Cfg struct {
+		*Server
+		Port int
+	}
+	var c Cfg
+	fs := newFlagSet()
+	assert.NoError(t, Register(fs, &c))
+	assert.Nil(t, c.Server, "Register() doesn't allocate it")
+	assert.NoError(t, fs.Parse([]string{"-port", "80"}))
+	assert.Nil(t, c.Server, "not allocated until its flag is parsed")
+	assert.NoError(t, fs.Parse([]string{"-host", "h"}))
+	assert.Equal(t, Cfg{Server: &Server{Host: "h"}, Port: 80}, c)
+}
+
+func TestRegister_Defaults(t *testing.T) {
+	c := Config{Port: 8080, Tags: []string{"default"}}
+	fs := newFlagSet()
+	assert.NoError(t, Register(fs, &c))
+	assert.NoError(t, fs.Parse(nil))
+	assert.Equal(t, 8080, c.Port)
+	assert.Equal(t, []string{"default"}, c.Tags)
+
+	var b strings.Builder
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	assert.Contains(t, b.String(), "-port value\n    \tlisten port (default 8080)")
+	assert.Contains(t, b.String(), "-v\tverbose output")
+}
+
+func TestRegister_Error(t *testing.T) {
+	var c Config
+	fs := newFlagSet()
+	assert.NoError(t, Register(fs, &c))
+	err := fs.Parse([]string{"-port=http"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "-port")
+
+	var s struct {
+		Labels map[string]string
+	}
+	err = Register(newFlagSet(), &s)
+	var errs *runtimescan.Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], runtimescan.ErrParseTag)
+	}
+
+	var p struct {
+		DB *struct {
+			Host string
+		}
+	}
+	err = Register(newFlagSet(), &p)
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], runtimescan.ErrParseTag)
+		assert.Contains(t, errs.Errors[0].Error(), "field 'DB' is a pointer of struct")
+	}
+
+	type server struct {
+		Host string
+	}
+	type proxy struct {
+		Host string
+	}
+	var d struct {
+		server
+		proxy
+	}
+	err = Register(newFlagSet(), &d)
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.ErrorIs(t, errs.Errors[0], runtimescan.ErrParseTag)
+		assert.Contains(t, errs.Errors[0].Error(), "flag 'host' of field 'proxy.Host' is already defined")
+	}
+
+	assert.Error(t, Register(newFlagSet(), c))
+}
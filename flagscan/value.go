@@ -0,0 +1,119 @@
+package flagscan
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/future-architect/tagscanner/internal/configscan"
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+// fieldValue is a flag.Value that writes the parsed value into the field of dest.
+type fieldValue struct {
+	dest any
+	tag  *flagTag
+	// value is the current value of the field.
+	value any
+	// set is true after the first Set() call. Slices are replaced by the first call and appended by the rest.
+	set bool
+}
+
+func (v *fieldValue) isBool() bool {
+	return v.tag.elemType.Kind() == reflect.Bool
+}
+
+func (v *fieldValue) String() string {
+	if v == nil || v.tag == nil {
+		return ""
+	}
+	return format(v.value)
+}
+
+func (v *fieldValue) Set(s string) error {
+	et := v.tag.elemType
+	var value any
+	if et.Kind() == reflect.Slice && !configscan.IsText(et) {
+		result := reflect.MakeSlice(et, 0, 0)
+		if v.set && v.value != nil {
+			result = reflect.ValueOf(v.value)
+		}
+		for _, item := range strings.Split(s, ",") {
+			ev, err := configscan.Parse(strings.TrimSpace(item), et.Elem())
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(et.Elem()).Elem()
+			if err := runtimescan.FuzzyAssign(elem, ev); err != nil {
+				return err
+			}
+			result = reflect.Append(result, elem)
+		}
+		value = result.Interface()
+	} else {
+		pv, err := configscan.Parse(s, et)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(et).Elem()
+		if err := runtimescan.FuzzyAssign(elem, pv); err != nil {
+			return err
+		}
+		value = elem.Interface()
+	}
+	if err := runtimescan.Set(v.dest, v.tag.path, value); err != nil {
+		return err
+	}
+	v.value = value
+	v.set = true
+	return nil
+}
+
+// Get implements flag.Getter.
+func (v *fieldValue) Get() any {
+	return v.value
+}
+
+// boolValue is a fieldValue of bool field. It allows -name without value.
+type boolValue struct {
+	*fieldValue
+}
+
+func (b *boolValue) String() string {
+	if b == nil || b.fieldValue == nil {
+		return ""
+	}
+	return b.fieldValue.String()
+}
+
+func (b *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+// format converts the value into the flag string. It is shown as the default value by flag.PrintDefaults().
+func format(value any) string {
+	if value == nil {
+		return ""
+	}
+	switch vv := value.(type) {
+	case time.Duration:
+		return vv.String()
+	case encoding.TextMarshaler:
+		b, err := vv.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Slice {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, format(v.Index(i).Interface()))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,153 @@
+// Package configscan has the code shared by envscan and flagscan. Both bind fields of nested structs
+// to flat names like DB_HOST and db.host, and parse the values from strings.
+package configscan
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+var (
+	// DurationType is the type of time.Duration. It is parsed by time.ParseDuration().
+	DurationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// IsText returns true if the type is converted from string by encoding.TextUnmarshaler.
+func IsText(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// IsSection returns true if the type is a child struct. Its fields get the name of the field as a prefix.
+func IsSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !IsText(t)
+}
+
+// IsSupported returns true if Parse() can convert the string into the value of the type.
+func IsSupported(t reflect.Type) bool {
+	if t == DurationType || IsText(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		return true
+	}
+	return false
+}
+
+// Parse converts the string into the value of the type. time.Duration, encoding.TextUnmarshaler
+// and the primitive types are available.
+func Parse(s string, t reflect.Type) (any, error) {
+	switch {
+	case t == DurationType:
+		return time.ParseDuration(s)
+	case IsText(t):
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+	return runtimescan.Str2PrimitiveValue(s, t)
+}
+
+// Prefixes keeps the prefixes of child structs while runtimescan parses tags.
+// Parents are always parsed before their fields, so the prefix of the parent is available.
+type Prefixes struct {
+	prefixes map[string]string
+	types    map[string]reflect.Type
+}
+
+// NewPrefixes creates Prefixes of the root struct type.
+func NewPrefixes(root reflect.Type) Prefixes {
+	return Prefixes{
+		prefixes: map[string]string{"": ""},
+		types:    map[string]reflect.Type{"": root},
+	}
+}
+
+// Name returns the name of the field at pathStr that has the prefixes of the parents.
+func (p Prefixes) Name(pathStr, name string) string {
+	return p.prefixes[parentPath(pathStr)] + name
+}
+
+// Enter registers the child struct at pathStr. name and sep are added to the prefix of its fields
+// unless it is an embedded struct without name. runtimescan doesn't traverse pointers of struct
+// that aren't embedded, so they are reported as runtimescan.ErrParseTag instead of being dropped.
+func (p Prefixes) Enter(fieldName, pathStr, name string, named bool, sep string, elemType reflect.Type) error {
+	parent := parentPath(pathStr)
+	f, _ := p.types[parent].FieldByName(fieldName)
+	if !f.Anonymous && f.Type.Kind() == reflect.Pointer {
+		return fmt.Errorf("field '%s' is a pointer of struct. use %s instead of %s: %w", pathStr, elemType, f.Type, runtimescan.ErrParseTag)
+	}
+	prefix := p.prefixes[parent]
+	if !f.Anonymous || named {
+		prefix += name + sep
+	}
+	p.prefixes[pathStr] = prefix
+	p.types[pathStr] = elemType
+	return nil
+}
+
+func parentPath(pathStr string) string {
+	if i := strings.LastIndex(pathStr, "."); i != -1 {
+		return pathStr[:i]
+	}
+	return ""
+}
+
+// AllocEmbedded returns a copy of the struct that ptr points. Nil embedded pointers of the copy are
+// allocated with zero values, because runtimescan.Encode() skips the fields in them, but every field
+// should be listed. The original struct is not modified. Embedded pointers of unexported struct
+// types can't be allocated by reflect and are kept nil.
+func AllocEmbedded(ptr any) any {
+	v := reflect.ValueOf(ptr).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	allocEmbedded(c.Elem())
+	return c.Interface()
+}
+
+func allocEmbedded(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+		switch {
+		case f.Type.Kind() == reflect.Struct:
+			allocEmbedded(fv)
+		case f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct && fv.CanSet():
+			// copy not to modify the struct that the original pointer points
+			p := reflect.New(f.Type.Elem())
+			if !fv.IsNil() {
+				p.Elem().Set(fv.Elem())
+			}
+			fv.Set(p)
+			allocEmbedded(p.Elem())
+		}
+	}
+}
+
+// ChildVisitor implements EnterChild() and LeaveChild() of runtimescan.Encoder that do nothing.
+// Names of the fields already have the prefixes of the child structs.
+type ChildVisitor struct{}
+
+// EnterChild does nothing.
+func (ChildVisitor) EnterChild(tag any) error {
+	return nil
+}
+
+// LeaveChild does nothing.
+func (ChildVisitor) LeaveChild(tag any) error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package configscan
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("1m30s", DurationType)
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, v)
+
+	v, err = Parse("127.0.0.1", reflect.TypeOf(net.IP{}))
+	assert.NoError(t, err)
+	assert.Equal(t, net.ParseIP("127.0.0.1"), v)
+
+	v, err = Parse("10", reflect.TypeOf(0))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), v)
+
+	_, err = Parse("ten", reflect.TypeOf(0))
+	assert.Error(t, err)
+
+	assert.False(t, IsSupported(reflect.TypeOf(map[string]string{})))
+	assert.False(t, IsSection(reflect.TypeOf(time.Time{})))
+}
+
+type server struct {
+	Host string
+}
+
+type config struct {
+	server
+	DB    server
+	Proxy *server
+}
+
+func TestPrefixes(t *testing.T) {
+	p := NewPrefixes(reflect.TypeOf(config{}))
+	st := reflect.TypeOf(server{})
+	assert.NoError(t, p.Enter("server", "server", "server", false, ".", st))
+	assert.NoError(t, p.Enter("DB", "DB", "db", false, ".", st))
+	assert.Equal(t, "host", p.Name("server.Host", "host"))
+	assert.Equal(t, "db.host", p.Name("DB.Host", "host"))
+
+	err := p.Enter("Proxy", "Proxy", "proxy", false, ".", st)
+	assert.True(t, errors.Is(err, runtimescan.ErrParseTag))
+}
+
+type Inner struct {
+	Host string
+}
+
+type Middle struct {
+	*Inner
+}
+
+type outer struct {
+	Middle
+	*server
+	Port int
+}
+
+func TestAllocEmbedded(t *testing.T) {
+	src := &outer{Port: 80}
+	c := AllocEmbedded(src).(*outer)
+	assert.Equal(t, &Inner{}, c.Inner)
+	assert.Nil(t, c.server, "unexported struct can't be allocated")
+	assert.Equal(t, 80, c.Port)
+	assert.Nil(t, src.Inner)
+
+	src.Inner = &Inner{Host: "h"}
+	c = AllocEmbedded(src).(*outer)
+	c.Inner.Host = "changed"
+	assert.Equal(t, "h", src.Inner.Host)
+}
@@ -0,0 +1,126 @@
+package mapscan
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+type decoder struct {
+	parser
+	c    *config
+	src  map[string]any
+	dest any
+}
+
+func (d *decoder) ExtractValue(tag any) (any, error) {
+	t := tag.(*mapTag)
+	value, ok := d.src[d.c.key(t)]
+	if !ok || value == nil {
+		return nil, runtimescan.Skip
+	}
+	return d.c.fromValue(value, t, d.current(t))
+}
+
+// current returns the current value of the field. It is nil if the field is a nil pointer.
+func (d *decoder) current(t *mapTag) any {
+	v, err := runtimescan.Get(d.dest, t.path)
+	if err != nil {
+		// in nil embedded pointer
+		return nil
+	}
+	return v
+}
+
+// Decode assigns values of src into dest. dest should be pointer of struct.
+//
+// map[string]any for nested structs and []map[string]any (or []any of maps) for slices of structs are converted.
+// Other values are assigned by runtimescan.FuzzyAssign(). Missing keys and nil values keep fields as they are,
+// including the fields of nested structs.
+func Decode(src map[string]any, dest any, opts ...Option) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest should be pointer of struct, but %T", dest)
+	}
+	return newConfig(opts).decodeStruct(src, v)
+}
+
+// decodeStruct decodes src into ptr.
+func (c *config) decodeStruct(src map[string]any, ptr reflect.Value) error {
+	d := &decoder{
+		parser: parser{t: ptr.Type().Elem()},
+		c:      c,
+		src:    src,
+		dest:   ptr.Interface(),
+	}
+	return runtimescan.Decode(d.dest, []string{c.tagKey}, d, runtimescan.JSONEmbedding())
+}
+
+// fromValue converts maps into nested structs. Nested structs are decoded into a copy of current
+// to keep the fields that src doesn't have.
+func (c *config) fromValue(value any, t *mapTag, current any) (any, error) {
+	switch t.elemType.Kind() {
+	case reflect.Struct:
+		if !isNested(t.elemType) {
+			return value, nil
+		}
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field '%s' should be map[string]any, but %T: %w", t.path, value, runtimescan.ErrAssignError)
+		}
+		ptr := reflect.New(t.elemType)
+		if cv := reflect.ValueOf(current); cv.Kind() == reflect.Pointer {
+			if !cv.IsNil() {
+				ptr.Elem().Set(cv.Elem())
+			}
+		} else if cv.IsValid() {
+			ptr.Elem().Set(cv)
+		}
+		if err := c.decodeStruct(m, ptr); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	case reflect.Slice:
+		et := t.elemType.Elem()
+		isPtr := et.Kind() == reflect.Pointer
+		if isPtr {
+			et = et.Elem()
+		}
+		if !isNested(et) {
+			return value, nil
+		}
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("field '%s' should be slice of map[string]any, but %T: %w", t.path, value, runtimescan.ErrAssignError)
+		}
+		if v.IsNil() {
+			return reflect.Zero(t.elemType).Interface(), nil
+		}
+		result := reflect.MakeSlice(t.elemType, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i).Interface()
+			if e == nil {
+				continue
+			}
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("element %d of field '%s' should be map[string]any, but %T: %w", i, t.path, e, runtimescan.ErrAssignError)
+			}
+			if m == nil {
+				continue
+			}
+			ptr := reflect.New(et)
+			if err := c.decodeStruct(m, ptr); err != nil {
+				return nil, err
+			}
+			if isPtr {
+				result.Index(i).Set(ptr)
+			} else {
+				result.Index(i).Set(ptr.Elem())
+			}
+		}
+		return result.Interface(), nil
+	}
+	return value, nil
+}
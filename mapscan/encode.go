@@ -0,0 +1,111 @@
+package mapscan
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+type encoder struct {
+	parser
+	c    *config
+	dest map[string]any
+}
+
+func (e *encoder) VisitField(tag, value any) error {
+	t := tag.(*mapTag)
+	v, err := e.c.toValue(value)
+	if err != nil {
+		return err
+	}
+	e.dest[e.c.key(t)] = v
+	return nil
+}
+
+func (e *encoder) EnterChild(tag any) error {
+	return nil
+}
+
+func (e *encoder) LeaveChild(tag any) error {
+	return nil
+}
+
+// Encode converts the struct into map[string]any. src should be struct or pointer of struct.
+//
+// Nested structs become map[string]any and slices of structs become []map[string]any.
+// Fields that have omitempty option (like `map:"name,omitempty"`) are omitted when they are empty.
+func Encode(src any, opts ...Option) (map[string]any, error) {
+	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, errors.New("src is nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("src should be struct or pointer of struct")
+	}
+	return newConfig(opts).encodeStruct(v)
+}
+
+func (c *config) encodeStruct(v reflect.Value) (map[string]any, error) {
+	if !v.CanAddr() {
+		// copy to make it addressable
+		cv := reflect.New(v.Type())
+		cv.Elem().Set(v)
+		v = cv.Elem()
+	}
+	e := &encoder{
+		parser: parser{t: v.Type()},
+		c:      c,
+		dest:   make(map[string]any),
+	}
+	err := runtimescan.Encode(v.Addr().Interface(), []string{c.tagKey}, e, runtimescan.OmitEmpty(), runtimescan.JSONEmbedding())
+	if err != nil {
+		return nil, err
+	}
+	return e.dest, nil
+}
+
+// toValue converts nested structs into maps.
+func (c *config) toValue(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Struct:
+		if isNested(v.Type()) {
+			return c.encodeStruct(v)
+		}
+	case reflect.Slice:
+		et := v.Type().Elem()
+		if et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		if !isNested(et) {
+			return value, nil
+		}
+		if v.IsNil() {
+			return []map[string]any(nil), nil
+		}
+		result := make([]map[string]any, v.Len())
+		for i := range result {
+			ev := v.Index(i)
+			if ev.Kind() == reflect.Pointer {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			m, err := c.encodeStruct(ev)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = m
+		}
+		return result, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,32 @@
+package mapscan_test
+
+import (
+	"fmt"
+
+	"github.com/future-architect/tagscanner/mapscan"
+)
+
+func ExampleEncode() {
+	type Address struct {
+		City string `map:"city"`
+	}
+	type User struct {
+		Name    string  `map:"name"`
+		Age     int     `map:"age,omitempty"`
+		Address Address `map:"address"`
+	}
+	m, _ := mapscan.Encode(&User{Name: "alice", Address: Address{City: "Tokyo"}})
+	fmt.Println(m)
+	// Output: map[address:map[city:Tokyo] name:alice]
+}
+
+func ExampleDecode() {
+	type User struct {
+		Name string `map:"name"`
+		Age  int    `map:"age"`
+	}
+	var u User
+	_ = mapscan.Decode(map[string]any{"name": "bob", "age": "20"}, &u)
+	fmt.Printf("%+v\n", u)
+	// Output: {Name:bob Age:20}
+}
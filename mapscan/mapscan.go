@@ -0,0 +1,122 @@
+// Package mapscan converts structs into map[string]any and vice versa by using runtimescan.
+//
+// Nested structs are converted into nested maps and slices of structs are converted into slices of maps.
+// Fields of embedded structs are promoted into the outer map by runtimescan.JSONEmbedding(), so the names
+// conflict as same as encoding/json: the shallowest field wins, a tagged field beats untagged ones and
+// ambiguous fields are dropped. An embedded struct that has a tag becomes a nested map.
+package mapscan
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+)
+
+// DefaultTagKey is a default tag key of mapscan.
+const DefaultTagKey = "map"
+
+// Option is an optional setting of Encode() and Decode().
+type Option func(c *config)
+
+type config struct {
+	tagKey string
+	naming runtimescan.Naming
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{tagKey: DefaultTagKey}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithTagKey changes the tag key. The default value is "map".
+func WithTagKey(tagKey string) Option {
+	return func(c *config) {
+		c.tagKey = tagKey
+	}
+}
+
+// WithNaming sets the naming strategy for fields without tag. By default, the field name is used as is.
+func WithNaming(n runtimescan.Naming) Option {
+	return func(c *config) {
+		c.naming = n
+	}
+}
+
+type mapTag struct {
+	name     string
+	key      string
+	path     string
+	elemType reflect.Type
+}
+
+// key returns the key of the map. The naming strategy is not applied when parsing because parsed tags are cached.
+func (c *config) key(t *mapTag) string {
+	if t.key != "" {
+		return t.key
+	}
+	return c.naming.Convert(t.name)
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// isNested returns true if the type is converted into map.
+// Struct types that implement encoding.TextMarshaler like time.Time are kept as is.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !t.Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// parser parses tags of one struct. Fields of embedded structs are traversed by runtimescan,
+// but nested structs are processed by another Encode() or Decode() call.
+type parser struct {
+	t reflect.Type
+}
+
+// isEmbedded returns true if the field at pathStr is an embedded struct without tag. Its fields are promoted.
+func (p parser) isEmbedded(pathStr, tagStr string, elemType reflect.Type) bool {
+	if tagStr != "" || !isNested(elemType) {
+		return false
+	}
+	t := p.t
+	var f reflect.StructField
+	for _, name := range strings.Split(pathStr, ".") {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		var ok bool
+		if f, ok = t.FieldByName(name); !ok {
+			return false
+		}
+		t = f.Type
+	}
+	return f.Anonymous
+}
+
+func (p parser) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	if tagStr == "-" {
+		return nil, runtimescan.Skip
+	}
+	o, err := runtimescan.ParseOptions(tagStr)
+	if err != nil {
+		return nil, fmt.Errorf("field '%s' of %s has invalid tag '%s': %w", pathStr, p.t, tagStr, runtimescan.ErrParseTag)
+	}
+	if p.isEmbedded(pathStr, tagStr, elemType) {
+		// runtimescan visits the fields inside instead
+		return nil, runtimescan.Skip
+	}
+	tag := &mapTag{
+		name:     name,
+		key:      o.Name,
+		path:     pathStr,
+		elemType: elemType,
+	}
+	if elemType.Kind() == reflect.Struct {
+		return tag, runtimescan.SkipTraverse
+	}
+	return tag, nil
+}
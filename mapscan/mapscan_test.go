@@ -0,0 +1,220 @@
+package mapscan
+
+import (
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/future-architect/tagscanner/runtimescan"
+	"github.com/stretchr/testify/assert"
+)
+
+type Address struct {
+	City string `map:"city"`
+	Zip  string `map:"zip,omitempty"`
+}
+
+type Base struct {
+	ID   int    `map:"id"`
+	Note string `map:"note"`
+}
+
+type User struct {
+	Base
+	Name      string            `map:"name"`
+	Note      string            `map:"note"`
+	Age       int               `map:"age,omitempty"`
+	Address   Address           `map:"address"`
+	Previous  *Address          `map:"previous"`
+	Addresses []Address         `map:"addresses"`
+	Friends   []*User           `map:"friends"`
+	CreatedAt time.Time         `map:"created_at"`
+	Labels    map[string]string `map:"labels"`
+	Secret    string            `map:"-"`
+}
+
+func TestEncode(t *testing.T) {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	u := User{
+		Base:      Base{ID: 1, Note: "base"},
+		Name:      "alice",
+		Note:      "outer",
+		Address:   Address{City: "Tokyo", Zip: "100"},
+		Addresses: []Address{{City: "Osaka"}},
+		Friends:   []*User{nil},
+		CreatedAt: createdAt,
+		Secret:    "secret",
+	}
+	m, err := Encode(&u)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":         1,
+		"name":       "alice",
+		"note":       "outer",
+		"address":    map[string]any{"city": "Tokyo", "zip": "100"},
+		"previous":   nil,
+		"addresses":  []map[string]any{{"city": "Osaka"}},
+		"friends":    []map[string]any{nil},
+		"created_at": createdAt,
+		"labels":     map[string]string(nil),
+	}, m)
+}
+
+func TestDecode(t *testing.T) {
+	src := map[string]any{
+		"id":       "10",
+		"name":     "bob",
+		"note":     "outer",
+		"address":  map[string]any{"city": "Tokyo"},
+		"previous": map[string]any{"city": "Kyoto"},
+		"addresses": []any{
+			map[string]any{"city": "Osaka", "zip": "530"},
+		},
+		"friends": []map[string]any{{"name": "carol"}, nil},
+		"labels":  map[string]string{"role": "admin"},
+		"Secret":  "secret",
+	}
+	var u User
+	err := Decode(src, &u)
+	assert.NoError(t, err)
+	assert.Equal(t, User{
+		Base:      Base{ID: 10},
+		Name:      "bob",
+		Note:      "outer",
+		Address:   Address{City: "Tokyo"},
+		Previous:  &Address{City: "Kyoto"},
+		Addresses: []Address{{City: "Osaka", Zip: "530"}},
+		Friends:   []*User{{Name: "carol"}, nil},
+		Labels:    map[string]string{"role": "admin"},
+	}, u)
+
+	t.Run("keep fields that src doesn't have", func(t *testing.T) {
+		u := User{
+			Base:     Base{ID: 1, Note: "keep"},
+			Address:  Address{City: "Tokyo", Zip: "1"},
+			Previous: &Address{City: "Kyoto"},
+		}
+		err := Decode(map[string]any{"id": 2, "address": map[string]any{"zip": "2"}, "previous": map[string]any{"zip": "3"}}, &u)
+		assert.NoError(t, err)
+		assert.Equal(t, Base{ID: 2, Note: "keep"}, u.Base)
+		assert.Equal(t, Address{City: "Tokyo", Zip: "2"}, u.Address)
+		assert.Equal(t, &Address{City: "Kyoto", Zip: "3"}, u.Previous)
+	})
+
+	t.Run("invalid nested value", func(t *testing.T) {
+		var u User
+		err := Decode(map[string]any{"address": "Tokyo"}, &u)
+		assert.ErrorIs(t, err.(*runtimescan.Errors).Errors[0], runtimescan.ErrAssignError)
+	})
+
+	t.Run("invalid dest", func(t *testing.T) {
+		var u User
+		assert.Error(t, Decode(src, u))
+	})
+}
+
+type embeddedA struct {
+	Name  string `map:"name"`
+	Code  string `map:"code"`
+	Title string
+}
+
+type EmbeddedB struct {
+	Code  string `map:"code"`
+	Title string `map:"Title"`
+	Level int    `map:"level"`
+}
+
+type embeddedC struct {
+	Level int `map:"level"`
+}
+
+type conflicted struct {
+	embeddedA
+	*EmbeddedB
+	Named embeddedC `map:"named"`
+}
+
+func TestEmbedding(t *testing.T) {
+	src := conflicted{
+		embeddedA: embeddedA{Name: "a", Code: "a", Title: "a"},
+		EmbeddedB: &EmbeddedB{Code: "b", Title: "b", Level: 1},
+		Named:     embeddedC{Level: 2},
+	}
+	m, err := Encode(&src)
+	assert.NoError(t, err)
+	// "Title": the tagged one wins, "code": ambiguous, "named": the tagged embedded struct is nested
+	assert.Equal(t, map[string]any{"name": "a", "Title": "b", "level": 1, "named": map[string]any{"level": 2}}, m)
+
+	var dest conflicted
+	err = Decode(map[string]any{"name": "a", "code": "c", "Title": "b", "level": 1}, &dest)
+	assert.NoError(t, err)
+	assert.Equal(t, conflicted{embeddedA: embeddedA{Name: "a"}, EmbeddedB: &EmbeddedB{Title: "b", Level: 1}}, dest)
+
+	dest = conflicted{}
+	err = Decode(map[string]any{"name": "a"}, &dest)
+	assert.NoError(t, err)
+	assert.Nil(t, dest.EmbeddedB, "nil embedded pointer is allocated only when its field is set")
+}
+
+func TestOptions(t *testing.T) {
+	type Config struct {
+		ServerName string
+		MaxConns   int `conf:"max"`
+	}
+	m, err := Encode(Config{ServerName: "api", MaxConns: 10}, WithTagKey("conf"), WithNaming(runtimescan.SnakeCase))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"server_name": "api", "max": 10}, m)
+
+	var c Config
+	err = Decode(m, &c, WithTagKey("conf"), WithNaming(runtimescan.SnakeCase))
+	assert.NoError(t, err)
+	assert.Equal(t, Config{ServerName: "api", MaxConns: 10}, c)
+
+	m, err = Encode(Config{ServerName: "api"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"ServerName": "api", "MaxConns": 0}, m)
+}
+
+type Inner struct {
+	Flag  bool
+	Score float64
+	Tags  []string
+}
+
+type Embedded struct {
+	Level uint8
+}
+
+type RoundTrip struct {
+	Embedded
+	Int       int
+	Int64     int64
+	String    string
+	Float     float64
+	Ptr       *int
+	Strings   []string
+	Inner     Inner
+	InnerPtr  *Inner
+	Inners    []Inner
+	InnerPtrs []*Inner
+	Counts    map[string]int
+}
+
+func TestRoundTrip(t *testing.T) {
+	f := func(src RoundTrip) bool {
+		m, err := Encode(&src)
+		if err != nil {
+			t.Log(err)
+			return false
+		}
+		var dest RoundTrip
+		if err := Decode(m, &dest); err != nil {
+			t.Log(err)
+			return false
+		}
+		return reflect.DeepEqual(src, dest)
+	}
+	assert.NoError(t, quick.Check(f, &quick.Config{MaxCount: 500}))
+}
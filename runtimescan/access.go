@@ -0,0 +1,170 @@
+package runtimescan
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// accessor locates a field from the root struct.
+type accessor struct {
+	index []int // index sequence from the root struct for reflect.Value.FieldByIndex
+	// unexported is true when the field or one of its parents is unexported.
+	unexported bool
+	// viaPtr is true when the index sequence goes through embedded pointers.
+	viaPtr bool
+}
+
+// locate returns the field of the root struct. root should be addressable.
+//
+// If the field is in a nil embedded pointer, locate allocates the struct when alloc is true.
+// Otherwise it returns invalid reflect.Value.
+func (a *accessor) locate(root reflect.Value, alloc bool) (reflect.Value, error) {
+	if !a.viaPtr {
+		return root.FieldByIndex(a.index), nil
+	}
+	fv := root
+	for i, x := range a.index {
+		if i > 0 && fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				if !fv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("can't set embedded pointer to unexported struct %s: %w", fv.Type().Elem(), ErrAssignError)
+				}
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		fv = fv.Field(x)
+	}
+	return fv, nil
+}
+
+// get returns the field of the root struct to assign it. See locate() for alloc.
+//
+// Unexported fields are exposed by package unsafe. Compiled parsers have them only when UnsafeAssignUnexported() is passed.
+func (a *accessor) get(root reflect.Value, alloc bool) (reflect.Value, error) {
+	fv, err := a.locate(root, alloc)
+	if err != nil || !fv.IsValid() {
+		return fv, err
+	}
+	if a.unexported {
+		fv = reflect.NewAt(fv.Type(), unsafe.Pointer(fv.UnsafeAddr())).Elem()
+	}
+	return fv, nil
+}
+
+// read returns the field of the root struct to read it. It returns invalid reflect.Value if the field is
+// in a nil embedded pointer.
+//
+// Unexported fields are copied into new values without package unsafe, so the caller can't modify the root struct
+// through them. ok is false if the value has something that can't be copied. If exposeUnsafe is true
+// (UnsafeAssignUnexported() is passed), unexported fields are exposed by package unsafe instead.
+func (a *accessor) read(root reflect.Value, exposeUnsafe bool) (fv reflect.Value, ok bool) {
+	fv, _ = a.locate(root, false)
+	if !fv.IsValid() || !a.unexported {
+		return fv, true
+	}
+	if exposeUnsafe {
+		return reflect.NewAt(fv.Type(), unsafe.Pointer(fv.UnsafeAddr())).Elem(), true
+	}
+	result := reflect.New(fv.Type()).Elem()
+	if !copyValue(result, fv, make(map[pointerKey]reflect.Value)) {
+		return reflect.Value{}, false
+	}
+	return result, true
+}
+
+type pointerKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+// copyValue deeply copies src that may be read-only into dst by kind specific getters.
+//
+// It returns false if src has func, chan, unsafe.Pointer or struct that has unexported fields
+// because they can't be copied without package unsafe. seen keeps copied pointers for cyclic values.
+func copyValue(dst, src reflect.Value, seen map[pointerKey]reflect.Value) bool {
+	switch src.Kind() {
+	case reflect.Bool:
+		dst.SetBool(src.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(src.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		dst.SetUint(src.Uint())
+	case reflect.Float32, reflect.Float64:
+		dst.SetFloat(src.Float())
+	case reflect.Complex64, reflect.Complex128:
+		dst.SetComplex(src.Complex())
+	case reflect.String:
+		dst.SetString(src.String())
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			if !copyValue(dst.Index(i), src.Index(i), seen) {
+				return false
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return true
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if !copyValue(s.Index(i), src.Index(i), seen) {
+				return false
+			}
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return true
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			v := reflect.New(src.Type().Elem()).Elem()
+			if !copyValue(k, iter.Key(), seen) || !copyValue(v, iter.Value(), seen) {
+				return false
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	case reflect.Pointer:
+		if src.IsNil() {
+			return true
+		}
+		key := pointerKey{t: src.Type(), p: src.Pointer()}
+		if p, ok := seen[key]; ok {
+			dst.Set(p)
+			return true
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[key] = p
+		if !copyValue(p.Elem(), src.Elem(), seen) {
+			return false
+		}
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return true
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		if !copyValue(e, src.Elem(), seen) {
+			return false
+		}
+		dst.Set(e)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			f := dst.Field(i)
+			if !f.CanSet() || !copyValue(f, src.Field(i), seen) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
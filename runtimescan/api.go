@@ -62,4 +62,6 @@ var (
 	// Skip is a flag to skip. This is returned by Parser interface's ParseTag() method to notify to add skip tag
 	// and ExtractValue() method of Decoder interface.
 	Skip = errors.New("skip")
+	// ErrInvalidPath is returned when field path or field path pattern has syntax error.
+	ErrInvalidPath = errors.New("invalid field path")
 )
@@ -87,6 +87,8 @@ func FuzzyAssign(dest, value any) error {
 			return nil
 		}
 		return fmt.Errorf("%v is not assignable to interface %v", vt, dv.Type())
+	} else if dv.Kind() != reflect.Pointer { // struct field that CanSet() is true
+		return fuzzyAssign(dv, dv.Type(), value)
 	} else {
 		if !dv.Elem().CanSet() { // If dv points to nil, create new instance
 			dv.Set(reflect.New(dv.Type().Elem()))
@@ -0,0 +1,345 @@
+package runtimescan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// DecoderFactory returns a Decoder for the i-th record. It is called from multiple goroutines.
+type DecoderFactory func(i int) (Decoder, error)
+
+// RecordError is an error of the record that batch functions fail to process.
+type RecordError struct {
+	Index int
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Concurrency sets the maximum number of goroutines of DecodeSlice(). The default value is runtime.GOMAXPROCS(0).
+func Concurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// DecodeSlice decodes n records and appends them to dest. dest should be *[]struct or *[]*struct.
+//
+// The records are decoded concurrently by the Decoder that factory returns for each index, and they are stored
+// in the order of indexes. All decoders should have the same type because the compiled parser of the first one is reused.
+// If factory fails, the error is reported as *RecordError of the index as same as decoding errors.
+// If some records fail, the other records are still appended and the returned Errors contains *RecordError per failure.
+// If ctx is canceled, DecodeSlice stops and returns ctx.Err() without modifying dest.
+func DecodeSlice(ctx context.Context, dest any, n int, tags []string, factory DecoderFactory, opts ...Option) error {
+	isPtrElem := IsPointerOfSliceOfPointerOfStruct(dest)
+	if !isPtrElem && !IsPointerOfSliceOfStruct(dest) {
+		return errors.New("dest should be *[]struct or *[]*struct")
+	}
+	if n <= 0 {
+		return nil
+	}
+	o := newOptions(opts)
+	sample, err := NewStructInstance(dest)
+	if err != nil {
+		return err
+	}
+	// the parser is compiled from the first decoder that factory returns successfully
+	recordErrors := make([]error, n)
+	firstIndex := n
+	var first Decoder
+	for i := 0; i < n; i++ {
+		d, err := factory(i)
+		if err != nil {
+			recordErrors[i] = err
+			continue
+		}
+		first, firstIndex = d, i
+		break
+	}
+	var v *parser
+	if first != nil {
+		v, err = getProgram(sample, tags, first, o, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	sliceValue := reflect.ValueOf(dest).Elem()
+	offset := sliceValue.Len()
+	result := reflect.AppendSlice(sliceValue, reflect.MakeSlice(sliceValue.Type(), n, n))
+	elemType := sliceValue.Type().Elem()
+
+	decodeRecord := func(i int) error {
+		decoder := first
+		if i != firstIndex {
+			var err error
+			decoder, err = factory(i)
+			if err != nil {
+				return err
+			}
+		}
+		ev := result.Index(offset + i)
+		var ptr reflect.Value
+		if isPtrElem {
+			ptr = reflect.New(elemType.Elem())
+			ev.Set(ptr)
+		} else {
+			ptr = ev.Addr()
+		}
+		return decode(ptr.Interface(), v, &decodeState{decoder: decoder, opts: o})
+	}
+
+	workers := o.concurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n-firstIndex {
+		workers = n - firstIndex
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				recordErrors[i] = decodeRecord(i)
+			}
+		}()
+	}
+feed:
+	for i := firstIndex; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sliceValue.Set(result)
+	var errs []error
+	for i, err := range recordErrors {
+		if err != nil {
+			errs = append(errs, &RecordError{Index: i, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return &Errors{
+			Errors: errs,
+		}
+	}
+	return nil
+}
+
+// RowDecoder is a Decoder of tabular sources like CSV, SQL rows or log lines.
+//
+// Next() advances to the next row and ExtractValue() extracts values of the current row.
+// Err() returns the error that stops the iteration.
+// As same as other parsers, the result of ParseTag() is cached per type, so it should not depend on the row source
+// like the header of CSV. Resolve such things in ExtractValue().
+type RowDecoder interface {
+	Decoder
+	Next() bool
+	Err() error
+}
+
+// DecodeEach decodes each row of rows into dest and calls fn with the row index. dest should be *struct.
+//
+// dest is reused for every row (it is reset to zero value before decoding), so the memory usage is constant.
+// If some rows fail, fn is not called for them and the returned Errors contains *RecordError per failure.
+// If fn returns an error, DecodeEach stops and returns it.
+func DecodeEach(dest any, tags []string, rows RowDecoder, fn func(i int) error, opts ...Option) error {
+	if !IsPointerOfStruct(dest) {
+		return errors.New("dest should be *struct")
+	}
+	o := newOptions(opts)
+	v, err := getProgram(dest, tags, rows, o, true)
+	if err != nil {
+		return err
+	}
+	dv := reflect.ValueOf(dest).Elem()
+	zero := reflect.Zero(dv.Type())
+	var errs []error
+	for i := 0; rows.Next(); i++ {
+		dv.Set(zero)
+		err := decode(dest, v, &decodeState{decoder: rows, opts: o})
+		if err != nil {
+			errs = append(errs, &RecordError{Index: i, Err: err})
+			continue
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return &Errors{
+			Errors: errs,
+		}
+	}
+	return nil
+}
+
+// DecodeAll decodes all rows of rows and appends them to dest. dest should be *[]struct or *[]*struct.
+//
+// If some rows fail, they are still appended and the returned Errors contains *RecordError per failure.
+func DecodeAll(dest any, tags []string, rows RowDecoder, opts ...Option) error {
+	isPtrElem := IsPointerOfSliceOfPointerOfStruct(dest)
+	if !isPtrElem && !IsPointerOfSliceOfStruct(dest) {
+		return errors.New("dest should be *[]struct or *[]*struct")
+	}
+	o := newOptions(opts)
+	sample, err := NewStructInstance(dest)
+	if err != nil {
+		return err
+	}
+	v, err := getProgram(sample, tags, rows, o, true)
+	if err != nil {
+		return err
+	}
+	sliceValue := reflect.ValueOf(dest).Elem()
+	elemType := sliceValue.Type().Elem()
+	var errs []error
+	for i := 0; rows.Next(); i++ {
+		var ev reflect.Value
+		if isPtrElem {
+			ev = reflect.New(elemType.Elem())
+		} else {
+			ev = reflect.New(elemType)
+		}
+		err := decode(ev.Interface(), v, &decodeState{decoder: rows, opts: o})
+		if err != nil {
+			errs = append(errs, &RecordError{Index: i, Err: err})
+		}
+		if isPtrElem {
+			sliceValue.Set(reflect.Append(sliceValue, ev))
+		} else {
+			sliceValue.Set(reflect.Append(sliceValue, ev.Elem()))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return &Errors{
+			Errors: errs,
+		}
+	}
+	return nil
+}
+
+// RecordEncoder is an optional interface of Encoder. EncodeAll() calls BeginRecord() and EndRecord() around each record.
+type RecordEncoder interface {
+	BeginRecord(index int) error
+	EndRecord(index int) error
+}
+
+// HeaderEncoder is an optional interface of Encoder. EncodeAll() calls Header() once before the first record
+// with the parsed tags of all visited fields in order.
+//
+// It is useful for writers that need column definitions like CSV, fixed-width format or SQL bulk insert.
+type HeaderEncoder interface {
+	Header(tags []any) error
+}
+
+// EncodeAll encodes all records of src. src should be []struct, []*struct or pointer of them.
+//
+// Errors of BeginRecord(), EndRecord() and Header() stop encoding. Other errors are aggregated
+// into Errors as *RecordError and the rest records are still encoded.
+//
+// If encoder implements HeaderEncoder, every record should visit exactly the fields in the header.
+// So polymorphic interface fields and fields that OmitEmpty() omits are rejected. Fields that don't have
+// values in the record (fields in nil embedded pointers and unexported fields that can't be copied)
+// are visited as nil.
+func EncodeAll(src any, tags []string, encoder Encoder, opts ...Option) error {
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Pointer {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Slice {
+		return errors.New("src should be []struct or []*struct")
+	}
+	elemType := sv.Type().Elem()
+	isPtrElem := elemType.Kind() == reflect.Pointer
+	if isPtrElem {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("src should be []struct or []*struct")
+	}
+	o := newOptions(opts)
+	v, err := getProgram(reflect.New(elemType).Interface(), tags, encoder, o, false)
+	if err != nil {
+		return err
+	}
+	if h, ok := encoder.(HeaderEncoder); ok {
+		var header []any
+		for i, op := range v.fieldOps {
+			if op != visitFieldOp {
+				continue
+			}
+			f := v.fields[i]
+			// these fields change visited fields per record, so they can't be columns
+			if f.hasKind {
+				return fmt.Errorf("polymorphic field '%s' can't be encoded by HeaderEncoder", f.path)
+			}
+			if o.omitEmpty && f.omitEmpty {
+				return fmt.Errorf("field '%s' has omitempty, but OmitEmpty() can't be used with HeaderEncoder", f.path)
+			}
+			header = append(header, f.tag)
+		}
+		if err := h.Header(header); err != nil {
+			return err
+		}
+		o.visitMissing = true
+	}
+	r, hasRecord := encoder.(RecordEncoder)
+	var errs []error
+	for i := 0; i < sv.Len(); i++ {
+		ev := sv.Index(i)
+		if isPtrElem {
+			if ev.IsNil() {
+				errs = append(errs, &RecordError{Index: i, Err: errors.New("record is nil")})
+				continue
+			}
+		} else {
+			ev = ev.Addr()
+		}
+		if hasRecord {
+			if err := r.BeginRecord(i); err != nil {
+				return err
+			}
+		}
+		if err := encode(encoder, v, ev.Interface(), o); err != nil {
+			errs = append(errs, &RecordError{Index: i, Err: err})
+		}
+		if hasRecord {
+			if err := r.EndRecord(i); err != nil {
+				return err
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &Errors{
+			Errors: errs,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package runtimescan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchRecord struct {
+	ID   int    `map:"id"`
+	Name string `map:"name"`
+}
+
+func recordFactory(i int) (Decoder, error) {
+	values := map[string]any{
+		"id":   strconv.Itoa(i),
+		"name": fmt.Sprintf("record-%d", i),
+	}
+	if i%10 == 3 {
+		values["id"] = "invalid"
+	}
+	return &mapDecoder{values: values}, nil
+}
+
+func TestDecodeSlice(t *testing.T) {
+	t.Run("slice of struct", func(t *testing.T) {
+		records := []batchRecord{{ID: -1}}
+		err := DecodeSlice(context.Background(), &records, 100, []string{"map"}, recordFactory, Concurrency(4))
+		assert.Error(t, err)
+		assert.Len(t, records, 101)
+		assert.Equal(t, batchRecord{ID: -1}, records[0])
+		assert.Equal(t, batchRecord{ID: 99, Name: "record-99"}, records[100])
+
+		var errs *Errors
+		assert.True(t, errors.As(err, &errs))
+		assert.Len(t, errs.Errors, 10)
+		for i, err := range errs.Errors {
+			var re *RecordError
+			assert.True(t, errors.As(err, &re))
+			assert.Equal(t, i*10+3, re.Index)
+		}
+	})
+
+	t.Run("slice of pointer", func(t *testing.T) {
+		var records []*batchRecord
+		factory := func(i int) (Decoder, error) {
+			return &mapDecoder{values: map[string]any{"id": i}}, nil
+		}
+		err := DecodeSlice(context.Background(), &records, 50, []string{"map"}, factory)
+		assert.NoError(t, err)
+		assert.Len(t, records, 50)
+		for i, r := range records {
+			assert.Equal(t, i, r.ID)
+		}
+	})
+
+	t.Run("factory error", func(t *testing.T) {
+		var records []batchRecord
+		factory := func(i int) (Decoder, error) {
+			if i == 2 {
+				return nil, errors.New("broken record")
+			}
+			return &mapDecoder{values: map[string]any{"id": i}}, nil
+		}
+		err := DecodeSlice(context.Background(), &records, 3, []string{"map"}, factory)
+		assert.EqualError(t, err, "1 errors: \n* record 2: broken record")
+		assert.Len(t, records, 3)
+	})
+
+	t.Run("factory error of the first record", func(t *testing.T) {
+		var records []batchRecord
+		factory := func(i int) (Decoder, error) {
+			if i < 2 {
+				return nil, errors.New("broken record")
+			}
+			return &mapDecoder{values: map[string]any{"id": i}}, nil
+		}
+		err := DecodeSlice(context.Background(), &records, 4, []string{"map"}, factory)
+		assert.EqualError(t, err, "2 errors: \n* record 0: broken record\n  record 1: broken record")
+		if assert.Len(t, records, 4) {
+			assert.Equal(t, 2, records[2].ID)
+			assert.Equal(t, 3, records[3].ID)
+		}
+
+		records = nil
+		err = DecodeSlice(context.Background(), &records, 2, []string{"map"}, func(i int) (Decoder, error) {
+			return nil, errors.New("broken record")
+		})
+		var errs *Errors
+		if assert.True(t, errors.As(err, &errs)) {
+			assert.Len(t, errs.Errors, 2)
+		}
+		assert.Len(t, records, 2)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		var records []batchRecord
+		ctx, cancel := context.WithCancel(context.Background())
+		factory := func(i int) (Decoder, error) {
+			if i == 5 {
+				cancel()
+			}
+			return &mapDecoder{values: map[string]any{"id": i}}, nil
+		}
+		err := DecodeSlice(ctx, &records, 1000, []string{"map"}, factory, Concurrency(1))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, records, 0)
+	})
+
+	t.Run("invalid dest", func(t *testing.T) {
+		var record batchRecord
+		err := DecodeSlice(context.Background(), &record, 1, []string{"map"}, recordFactory)
+		assert.Error(t, err)
+	})
+}
+
+// csvRows is a RowDecoder of simple comma separated rows.
+type csvRows struct {
+	header []string
+	rows   [][]string
+	row    []string
+	err    error
+}
+
+func (r *csvRows) ParseTag(name, tagKey, tagStr, pathStr string, eType reflect.Type) (any, error) {
+	return tagStr, nil
+}
+
+func (r *csvRows) ExtractValue(tag any) (any, error) {
+	for i, h := range r.header {
+		if h == tag.(string) {
+			return r.row[i], nil
+		}
+	}
+	return nil, Skip
+}
+
+func (r *csvRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	r.row = strings.Split(r.rows[0][0], ",")
+	r.rows = r.rows[1:]
+	return true
+}
+
+func (r *csvRows) Err() error {
+	return r.err
+}
+
+func newCSVRows(lines ...string) *csvRows {
+	r := &csvRows{header: strings.Split(lines[0], ",")}
+	for _, l := range lines[1:] {
+		r.rows = append(r.rows, []string{l})
+	}
+	return r
+}
+
+func TestDecodeEach(t *testing.T) {
+	t.Run("each", func(t *testing.T) {
+		rows := newCSVRows("id,name", "1,alice", "x,bob", "3,")
+		var record batchRecord
+		var result []batchRecord
+		err := DecodeEach(&record, []string{"map"}, rows, func(i int) error {
+			result = append(result, record)
+			return nil
+		})
+		assert.EqualError(t, err, "1 errors: \n* record 1: 1 errors: \n* strconv.ParseInt: parsing \"x\": invalid syntax")
+		assert.Equal(t, []batchRecord{{ID: 1, Name: "alice"}, {ID: 3}}, result)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		rows := newCSVRows("id,name", "1,alice", "2,bob")
+		var record batchRecord
+		stop := errors.New("stop")
+		err := DecodeEach(&record, []string{"map"}, rows, func(i int) error {
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Len(t, rows.rows, 1)
+	})
+
+	t.Run("rows error", func(t *testing.T) {
+		rows := newCSVRows("id,name")
+		rows.err = errors.New("connection closed")
+		var record batchRecord
+		err := DecodeEach(&record, []string{"map"}, rows, func(i int) error {
+			return nil
+		})
+		assert.EqualError(t, err, "1 errors: \n* connection closed")
+	})
+}
+
+func TestDecodeAll(t *testing.T) {
+	t.Run("slice of struct", func(t *testing.T) {
+		rows := newCSVRows("name,id", "alice,1", "bob,2")
+		var result []batchRecord
+		err := DecodeAll(&result, []string{"map"}, rows)
+		assert.NoError(t, err)
+		assert.Equal(t, []batchRecord{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, result)
+	})
+
+	t.Run("slice of pointer", func(t *testing.T) {
+		rows := newCSVRows("name,id", "alice,1", "bob,x")
+		var result []*batchRecord
+		err := DecodeAll(&result, []string{"map"}, rows)
+		var errs *Errors
+		assert.True(t, errors.As(err, &errs))
+		var re *RecordError
+		assert.True(t, errors.As(errs.Errors[0], &re))
+		assert.Equal(t, 1, re.Index)
+		assert.Equal(t, []*batchRecord{{ID: 1, Name: "alice"}, {Name: "bob"}}, result)
+	})
+}
+
+// csvWriter is an Encoder that writes comma separated rows with header.
+type csvWriter struct {
+	lines []string
+	row   []string
+}
+
+func (w *csvWriter) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	if tagStr == "" {
+		return nil, Skip
+	}
+	return tagStr, nil
+}
+
+func (w *csvWriter) Header(tags []any) error {
+	var names []string
+	for _, t := range tags {
+		names = append(names, t.(string))
+	}
+	w.lines = append(w.lines, strings.Join(names, ","))
+	return nil
+}
+
+func (w *csvWriter) BeginRecord(index int) error {
+	w.row = nil
+	return nil
+}
+
+func (w *csvWriter) VisitField(tag, value any) error {
+	w.row = append(w.row, fmt.Sprint(value))
+	return nil
+}
+
+func (w *csvWriter) EnterChild(tag any) error {
+	return nil
+}
+
+func (w *csvWriter) LeaveChild(tag any) error {
+	return nil
+}
+
+func (w *csvWriter) EndRecord(index int) error {
+	w.lines = append(w.lines, strings.Join(w.row, ","))
+	return nil
+}
+
+func TestEncodeAll(t *testing.T) {
+	t.Run("slice of struct", func(t *testing.T) {
+		w := &csvWriter{}
+		err := EncodeAll([]batchRecord{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, []string{"map"}, w)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id,name", "1,alice", "2,bob"}, w.lines)
+	})
+
+	t.Run("pointer of slice of pointer", func(t *testing.T) {
+		w := &csvWriter{}
+		records := []*batchRecord{{ID: 1, Name: "alice"}, nil, {ID: 3, Name: "carol"}}
+		err := EncodeAll(&records, []string{"map"}, w)
+		assert.EqualError(t, err, "1 errors: \n* record 1: record is nil")
+		assert.Equal(t, []string{"id,name", "1,alice", "3,carol"}, w.lines)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		w := &csvWriter{}
+		err := EncodeAll([]batchRecord{}, []string{"map"}, w)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"id,name"}, w.lines)
+	})
+
+	t.Run("invalid src", func(t *testing.T) {
+		err := EncodeAll(&batchRecord{}, []string{"map"}, &csvWriter{})
+		assert.Error(t, err)
+	})
+
+	t.Run("fields that don't match header", func(t *testing.T) {
+		type omitRecord struct {
+			ID   int    `map:"id"`
+			Name string `map:"name,omitempty"`
+		}
+		w := &csvWriter{}
+		err := EncodeAll([]omitRecord{{ID: 1}}, []string{"map"}, w, OmitEmpty())
+		assert.EqualError(t, err, "field 'Name' has omitempty, but OmitEmpty() can't be used with HeaderEncoder")
+		assert.Empty(t, w.lines)
+
+		w = &csvWriter{}
+		err = EncodeAll([]omitRecord{{ID: 1}}, []string{"map"}, w)
+		assert.NoError(t, err)
+		assert.Len(t, w.lines, 2)
+
+		err = EncodeAll([]event{{ID: "1"}}, []string{"map"}, &csvWriter{}, WithTypeRegistry(newEventRegistry()))
+		assert.EqualError(t, err, "polymorphic field 'Payload' can't be encoded by HeaderEncoder")
+	})
+
+	t.Run("fields without values are visited as nil", func(t *testing.T) {
+		type Base struct {
+			Host string `map:"host"`
+		}
+		type config struct {
+			*Base
+			Port  int    `map:"port"`
+			check func() `map:"check"`
+		}
+		w := &csvWriter{}
+		err := EncodeAll([]config{{Base: &Base{Host: "a"}, Port: 1}, {Port: 2}}, []string{"map"}, w, IncludeUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"host,port,check", "a,1,<nil>", "<nil>,2,<nil>"}, w.lines)
+
+		m := &mapEncoder{result: map[string]any{}}
+		err = EncodeAll([]config{{Port: 2}}, []string{"map"}, m, IncludeUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"port": 2}, m.result, "without HeaderEncoder, they are skipped")
+	})
+}
@@ -0,0 +1,222 @@
+package runtimescan
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheStats is a statistics of the parser cache.
+type CacheStats struct {
+	// Size is a current number of compiled parsers.
+	Size int
+	// MaxSize is a size bound that SetCacheSize() sets. 0 means unbounded.
+	MaxSize int
+	// Programs is a current number of programs that Include(), Exclude() and JSONEmbedding() are applied.
+	// Each parser keeps at most maxPrograms of them.
+	Programs  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+type cacheEntry struct {
+	key    parserCacheKey
+	parser *parser
+	elem   *list.Element
+}
+
+// parserCache is a cache of compiled parsers.
+//
+// Lookups read entries from sync.Map without lock. The LRU order is maintained under lock
+// only when the cache is bounded by SetCacheSize().
+type parserCache struct {
+	// 64-bit values for atomic operations are placed first to be aligned on 32-bit platforms.
+	maxSize   int64
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	// lock guards order. New entries and evictions also take it.
+	lock    sync.Mutex
+	entries sync.Map
+	order   *list.List
+}
+
+func newParserCache() *parserCache {
+	return &parserCache{
+		order: list.New(),
+	}
+}
+
+func (c *parserCache) get(key parserCacheKey) (*parser, bool) {
+	v, ok := c.entries.Load(key)
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	e := v.(*cacheEntry)
+	if atomic.LoadInt64(&c.maxSize) > 0 {
+		c.lock.Lock()
+		// MoveToFront does nothing if the entry is already evicted
+		c.order.MoveToFront(e.elem)
+		c.lock.Unlock()
+	}
+	return e.parser, true
+}
+
+func (c *parserCache) put(key parserCacheKey, p *parser) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e := &cacheEntry{key: key, parser: p}
+	if v, ok := c.entries.Load(key); ok {
+		e.elem = v.(*cacheEntry).elem
+		e.elem.Value = e
+		c.order.MoveToFront(e.elem)
+	} else {
+		e.elem = c.order.PushFront(e)
+	}
+	c.entries.Store(key, e)
+	c.evict()
+}
+
+// evict removes least recently used entries over maxSize. Caller should have the lock.
+func (c *parserCache) evict() {
+	maxSize := int(atomic.LoadInt64(&c.maxSize))
+	if maxSize <= 0 {
+		return
+	}
+	for c.order.Len() > maxSize {
+		e := c.order.Back()
+		c.order.Remove(e)
+		c.entries.Delete(e.Value.(*cacheEntry).key)
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// maxPrograms is the max number of programs per parser. Masks may be different per request,
+// so the programs are bounded not to keep every combination.
+const maxPrograms = 16
+
+// programCache is a LRU cache of programs that options are applied. See options.program().
+type programCache struct {
+	lock  sync.Mutex
+	items map[programKey]*list.Element
+	order list.List
+}
+
+type programEntry struct {
+	key     programKey
+	program *parser
+}
+
+func (c *programCache) get(key programKey) (*parser, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*programEntry).program, true
+}
+
+// put stores the program. If another goroutine has stored the program of the same key, it is returned instead.
+func (c *programCache) put(key programKey, p *parser) *parser {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*programEntry).program
+	}
+	if c.items == nil {
+		c.items = make(map[programKey]*list.Element)
+	}
+	c.items[key] = c.order.PushFront(&programEntry{key: key, program: p})
+	for c.order.Len() > maxPrograms {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*programEntry).key)
+	}
+	return p
+}
+
+func (c *programCache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
+
+var parsers = newParserCache()
+
+// ResetCache removes all compiled parsers and statistics.
+//
+// It is for tests that redefine tag grammars and for types built by reflect.StructOf().
+func ResetCache() {
+	parsers.reset()
+}
+
+func (c *parserCache) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries.Range(func(key, value any) bool {
+		c.entries.Delete(key)
+		return true
+	})
+	// elements are removed one by one instead of order.Init(). Removed elements don't belong to
+	// the list, so MoveToFront() by get() that loaded the entry before reset does nothing.
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		c.order.Remove(e)
+	}
+	atomic.StoreUint64(&c.hits, 0)
+	atomic.StoreUint64(&c.misses, 0)
+	atomic.StoreUint64(&c.evictions, 0)
+}
+
+// SetCacheSize sets the max number of compiled parsers. Least recently used parsers are evicted
+// when the cache is full. 0 (default) means unbounded.
+//
+// In unbounded mode, lookups don't take any lock. In bounded mode, each hit updates the LRU order under lock.
+func SetCacheSize(size int) {
+	parsers.lock.Lock()
+	defer parsers.lock.Unlock()
+	atomic.StoreInt64(&parsers.maxSize, int64(size))
+	parsers.evict()
+}
+
+// GetCacheStats returns the statistics of the parser cache.
+func GetCacheStats() CacheStats {
+	parsers.lock.Lock()
+	defer parsers.lock.Unlock()
+	var programs int
+	parsers.entries.Range(func(key, value any) bool {
+		programs += value.(*cacheEntry).parser.programs.len()
+		return true
+	})
+	return CacheStats{
+		Size:      parsers.order.Len(),
+		Programs:  programs,
+		MaxSize:   int(atomic.LoadInt64(&parsers.maxSize)),
+		Hits:      atomic.LoadUint64(&parsers.hits),
+		Misses:    atomic.LoadUint64(&parsers.misses),
+		Evictions: atomic.LoadUint64(&parsers.evictions),
+	}
+}
+
+// Prewarm compiles parsers for samples at startup. Each sample should be a pointer of struct.
+//
+// tags and p should be as same as Decode() or Encode() calls.
+func Prewarm(tags []string, p Parser, samples ...any) error {
+	var errors []error
+	for _, s := range samples {
+		if _, err := getParser(s, tags, p, false); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) > 0 {
+		return &Errors{
+			Errors: errors,
+		}
+	}
+	return nil
+}
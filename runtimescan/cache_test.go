@@ -0,0 +1,110 @@
+package runtimescan
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserCache(t *testing.T) {
+	type A struct {
+		Value string `map:"value"`
+	}
+	type B struct {
+		Value string `map:"value"`
+	}
+	type C struct {
+		Value string `map:"value"`
+	}
+	defer func() {
+		SetCacheSize(0)
+		ResetCache()
+	}()
+
+	t.Run("stats and reset", func(t *testing.T) {
+		ResetCache()
+		d := mapDecoder{values: map[string]any{"value": "v"}}
+		assert.NoError(t, Decode(&A{}, []string{"map"}, &d))
+		assert.NoError(t, Decode(&A{}, []string{"map"}, &d))
+		assert.NoError(t, Decode(&B{}, []string{"map"}, &d))
+		assert.Equal(t, CacheStats{Size: 2, Hits: 1, Misses: 2}, GetCacheStats())
+
+		ResetCache()
+		assert.Equal(t, CacheStats{}, GetCacheStats())
+	})
+
+	t.Run("LRU eviction", func(t *testing.T) {
+		ResetCache()
+		SetCacheSize(2)
+		d := mapDecoder{}
+		assert.NoError(t, Decode(&A{}, []string{"map"}, &d))
+		assert.NoError(t, Decode(&B{}, []string{"map"}, &d))
+		assert.NoError(t, Decode(&A{}, []string{"map"}, &d)) // A is used recently
+		assert.NoError(t, Decode(&C{}, []string{"map"}, &d)) // B is evicted
+		stats := GetCacheStats()
+		assert.Equal(t, 2, stats.Size)
+		assert.Equal(t, 2, stats.MaxSize)
+		assert.Equal(t, uint64(1), stats.Evictions)
+
+		_, ok := parsers.get(parserCacheKey{Type: reflect.TypeOf(&A{}), Parser: reflect.TypeOf(d), Tag: "map"})
+		assert.True(t, ok)
+		_, ok = parsers.get(parserCacheKey{Type: reflect.TypeOf(&B{}), Parser: reflect.TypeOf(d), Tag: "map"})
+		assert.False(t, ok)
+
+		SetCacheSize(1)
+		assert.Equal(t, 1, GetCacheStats().Size)
+	})
+
+	t.Run("stale entry after reset", func(t *testing.T) {
+		c := newParserCache()
+		atomic.StoreInt64(&c.maxSize, 2)
+		key := parserCacheKey{Type: reflect.TypeOf(&A{}), Tag: "map"}
+		c.put(key, &parser{})
+		v, _ := c.entries.Load(key)
+		c.reset()
+		// get() that loaded the entry before reset moves it
+		c.order.MoveToFront(v.(*cacheEntry).elem)
+		assert.Equal(t, 0, c.order.Len())
+		assert.Nil(t, c.order.Front())
+
+		c.put(key, &parser{})
+		n := 0
+		for e := c.order.Front(); e != nil; e = e.Next() {
+			n++
+		}
+		assert.Equal(t, 1, n)
+		assert.Equal(t, 1, c.order.Len())
+	})
+
+	t.Run("prewarm", func(t *testing.T) {
+		SetCacheSize(0)
+		ResetCache()
+		err := Prewarm([]string{"map"}, &mapDecoder{}, &A{}, &B{}, &C{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, GetCacheStats().Size)
+
+		assert.NoError(t, Decode(&C{}, []string{"map"}, &mapDecoder{}))
+		assert.Equal(t, uint64(1), GetCacheStats().Hits)
+
+		err = Prewarm([]string{"map"}, &mapDecoder{}, A{})
+		assert.Error(t, err)
+	})
+}
+
+func BenchmarkParserCache_Parallel(b *testing.B) {
+	type A struct {
+		Value string `map:"value"`
+	}
+	d := &mapDecoder{values: map[string]any{"value": "v"}}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var a A
+		for pb.Next() {
+			if err := Decode(&a, []string{"map"}, d); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// TagDecodable is implemented by field types that decode themselves.
+//
+// If the field type implements it, Decode() passes the value that ExtractValue() returns
+// to DecodeTag() instead of FuzzyAssign(). It lets domain types like Money or Email own
+// their parsing regardless of the source format.
+// tagKey is the first tag key passed to Decode().
+type TagDecodable interface {
+	DecodeTag(tagKey string, raw any) error
+}
+
+// TagEncodable is implemented by field types that encode themselves.
+//
+// If the field type implements it, Encode() passes the value that EncodeTag() returns
+// to VisitField() instead of the field value.
+type TagEncodable interface {
+	EncodeTag(tagKey string) (any, error)
+}
+
+var (
+	tagDecodableType = reflect.TypeOf((*TagDecodable)(nil)).Elem()
+	tagEncodableType = reflect.TypeOf((*TagEncodable)(nil)).Elem()
+)
+
+func isTagDecodable(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(tagDecodableType)
+}
+
+func isTagEncodable(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(tagEncodableType)
+}
+
+// assignField assigns the value to the field. fv should be addressable.
+func assignField(fv reflect.Value, f *field, value any, tagKey string) error {
+	if !f.decodable {
+		return FuzzyAssign(fv, value)
+	}
+	if f.isPtr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(f.eType))
+		}
+		return fv.Interface().(TagDecodable).DecodeTag(tagKey, value)
+	}
+	return fv.Addr().Interface().(TagDecodable).DecodeTag(tagKey, value)
+}
+
+// fieldValue returns the value of the field that is passed to VisitField(). fv should be addressable.
+func fieldValue(fv reflect.Value, f *field, tagKey string) (any, error) {
+	if f.isPtr {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	if f.encodable {
+		return fv.Addr().Interface().(TagEncodable).EncodeTag(tagKey)
+	}
+	return fv.Interface(), nil
+}
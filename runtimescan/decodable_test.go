@@ -0,0 +1,100 @@
+package runtimescan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type money struct {
+	Amount   int
+	Currency string
+}
+
+func (m *money) DecodeTag(tagKey string, raw any) error {
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("money should be string, but %T", raw)
+	}
+	parts := strings.SplitN(s, " ", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid money: '%s'", s)
+	}
+	amount, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return err
+	}
+	m.Amount = amount
+	m.Currency = parts[1]
+	return nil
+}
+
+func (m money) EncodeTag(tagKey string) (any, error) {
+	return fmt.Sprintf("%d %s", m.Amount, m.Currency), nil
+}
+
+type email string
+
+func (e *email) DecodeTag(tagKey string, raw any) error {
+	s := fmt.Sprint(raw)
+	if !strings.Contains(s, "@") {
+		return fmt.Errorf("invalid email: '%s' (%s)", s, tagKey)
+	}
+	*e = email(strings.ToLower(s))
+	return nil
+}
+
+func TestTagDecodable(t *testing.T) {
+	type Order struct {
+		Price    money  `map:"price"`
+		Discount *money `map:"discount"`
+		Email    email  `map:"email"`
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"price":    "100 JPY",
+				"discount": "10 JPY",
+				"email":    "User@Example.com",
+			},
+		}
+		o := Order{}
+		err := Decode(&o, []string{"map"}, &d)
+		assert.NoError(t, err)
+		assert.Equal(t, money{Amount: 100, Currency: "JPY"}, o.Price)
+		assert.Equal(t, &money{Amount: 10, Currency: "JPY"}, o.Discount)
+		assert.Equal(t, email("user@example.com"), o.Email)
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"email": "invalid",
+			},
+		}
+		o := Order{}
+		err := Decode(&o, []string{"map"}, &d)
+		assert.EqualError(t, err, "1 errors: \n* invalid email: 'invalid' (map)")
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		e := mapEncoder{
+			result: make(map[string]any),
+		}
+		o := Order{
+			Price: money{Amount: 100, Currency: "JPY"},
+			Email: "user@example.com",
+		}
+		err := Encode(&o, []string{"map"}, &e)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"price":    "100 JPY",
+			"discount": nil,
+			"email":    email("user@example.com"),
+		}, e.result)
+	})
+}
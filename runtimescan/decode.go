@@ -1,51 +1,212 @@
 package runtimescan
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 )
 
 // Decode convert from some source into struct by using tag information.
-func Decode(dest any, tags []string, decoder Decoder) error {
-	v, err := getParser(dest, tags, decoder)
+func Decode(dest any, tags []string, decoder Decoder, opts ...Option) error {
+	o := newOptions(opts)
+	v, err := getProgram(dest, tags, decoder, o, true)
 	if err != nil {
 		return err
 	}
-	return decode(dest, v, decoder)
+	return decode(dest, v, &decodeState{decoder: decoder, opts: o})
 }
 
-func decode(dest any, v *parser, decoder Decoder) error {
-	current := reflect.ValueOf(dest).Elem()
-	stack := []reflect.Value{current}
-	var errors []error
+// DecodeWithResult is as same as Decode() but it also returns which fields were set.
+//
+// The result is returned even if decoding some fields fails.
+func DecodeWithResult(dest any, tags []string, decoder Decoder, opts ...Option) (*DecodeResult, error) {
+	o := newOptions(opts)
+	v, err := getProgram(dest, tags, decoder, o, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &DecodeResult{}
+	err = decode(dest, v, &decodeState{decoder: decoder, opts: o, result: result})
+	return result, err
+}
+
+type decodeState struct {
+	decoder Decoder
+	typed   TypedDecoder
+	opts    *options
+	result  *DecodeResult
+	errors  []error
+}
+
+func decode(dest any, v *parser, s *decodeState) error {
+	if s.opts == nil {
+		s.opts = &options{}
+	}
+	s.typed, _ = s.decoder.(TypedDecoder)
+	s.decodeStruct(reflect.ValueOf(dest).Elem(), v, "")
+	if len(s.errors) > 0 {
+		return &Errors{
+			Errors: s.errors,
+		}
+	}
+	return nil
+}
+
+func (s *decodeState) addError(err error) {
+	if err != nil {
+		s.errors = append(s.errors, err)
+	}
+}
+
+// decodeStruct decodes fields of the struct. prefix is a path of the struct when it is decoded as
+// a concrete type of polymorphic interface field.
+func (s *decodeState) decodeStruct(root reflect.Value, v *parser, prefix string) {
+	s.addError(callHook(root, hasBeforeDecode, v.tagKey))
+	if s.opts.tracer != nil {
+		traceSkipped(s.opts.tracer, TraceDecode, v, prefix)
+	}
 	for i, op := range v.fieldOps {
-		index := v.fieldIndexes[i]
 		field := v.fields[i]
 		switch op {
 		case visitFieldOp:
-			fv := current.Field(index)
-			value, err := decoder.ExtractValue(field.tag)
-			if err == Skip {
-				continue
-			} else if err != nil {
-				errors = append(errors, err)
-				continue
+			path := joinPath(prefix, field.path)
+			var e *TraceEvent
+			var start time.Time
+			if s.opts.tracer != nil {
+				e = &TraceEvent{Op: TraceDecode, Path: path, Tag: field.tag}
+				start = time.Now()
+			}
+			fv, err := field.get(root, false)
+			// a field in nil embedded pointer is decoded into a temporary value first
+			// and the pointer is allocated only when the value is assigned
+			pending := err == nil && !fv.IsValid()
+			if pending {
+				fv = reflect.New(field.fieldType()).Elem()
+			}
+			kind := resultFailed
+			if err == nil && field.hasKind {
+				kind, err = s.decodePolymorphic(fv, v, field, path, e)
+			} else if err == nil {
+				kind, err = s.decodeField(fv, v, field, e)
 			}
-			err = FuzzyAssign(fv, value)
-			if err != nil {
-				errors = append(errors, err)
+			if pending && (kind == resultSet || kind == resultDefaulted) {
+				var dest reflect.Value
+				dest, err = field.get(root, true)
+				if err == nil {
+					dest.Set(fv)
+				} else {
+					kind = resultFailed
+				}
+			}
+			s.addError(err)
+			s.result.add(kind, path)
+			if e != nil {
+				e.Status = kind.traceStatus()
+				e.Err = err
+				e.Elapsed = time.Since(start)
+				s.opts.tracer.TraceField(e)
 			}
 		case visitChildOp:
-			current := current.Field(index)
-			stack = append(stack, current)
+			s.addError(callChildHook(root, v.children[i], hasBeforeDecode, v.tagKey, true))
 		case leaveChildOp:
-			stack = stack[:len(stack)-1]
-			current = stack[len(stack)-1]
+			s.addError(callChildHook(root, v.children[i], hasAfterDecode, v.tagKey, true))
 		}
 	}
-	if len(errors) > 0 {
-		return &Errors{
-			Errors: errors,
+	s.addError(callHook(root, hasAfterDecode, v.tagKey))
+}
+
+// decodeField extracts the value and assigns it to the field. e is filled when tracing.
+func (s *decodeState) decodeField(fv reflect.Value, v *parser, field *field, e *TraceEvent) (resultKind, error) {
+	if s.typed != nil && field.typed != untyped {
+		err := decodeTyped(s.typed, fv, field)
+		if e != nil {
+			e.Conversion = field.typed.method()
+			if err == nil {
+				e.Value = fv.Interface()
+			}
 		}
+		if err == Skip {
+			if e != nil {
+				e.SkipReason = field.typed.method() + " returned Skip"
+			}
+			return resultSkipped, nil
+		} else if err != nil {
+			return resultFailed, err
+		}
+		return resultSet, nil
 	}
-	return nil
+	value, err := s.decoder.ExtractValue(field.tag)
+	if err == Skip {
+		if e != nil {
+			e.SkipReason = "ExtractValue returned Skip"
+		}
+		return resultSkipped, nil
+	} else if err != nil {
+		return resultFailed, err
+	}
+	kind := resultSet
+	if d, ok := value.(DefaultValue); ok {
+		value = d.Value
+		kind = resultDefaulted
+	}
+	if e != nil {
+		e.Value = value
+		e.Conversion = conversion(value, field, fv.Type())
+	}
+	err = assignField(fv, field, value, v.tagKey)
+	if err != nil {
+		return resultFailed, err
+	}
+	return kind, nil
+}
+
+// decodePolymorphic extracts discriminator and decodes the registered concrete type into the interface field.
+// Fields of the concrete type are reported by the nested decodeStruct(). e is filled when tracing.
+func (s *decodeState) decodePolymorphic(fv reflect.Value, v *parser, field *field, path string, e *TraceEvent) (resultKind, error) {
+	value, err := s.decoder.ExtractValue(field.kindTag)
+	if err == Skip {
+		if e != nil {
+			e.SkipReason = "ExtractValue returned Skip for discriminator"
+		}
+		return resultSkipped, nil
+	} else if err != nil {
+		return resultFailed, err
+	}
+	if d, ok := value.(DefaultValue); ok {
+		value = d.Value
+	}
+	if e != nil {
+		e.Value = value
+	}
+	if s.opts.registry == nil {
+		return resultFailed, fmt.Errorf("field '%s' has '%s' tag, but TypeRegistry is not passed", path, DiscriminatorTag)
+	}
+	rt, err := s.opts.registry.lookup(fmt.Sprint(value), path)
+	if err != nil {
+		return resultFailed, err
+	}
+	instance := reflect.New(rt.t)
+	cv, err := getParser(instance.Interface(), v.tags, s.decoder, v.unexported)
+	if err != nil {
+		return resultFailed, err
+	}
+	s.decodeStruct(instance.Elem(), cv, path)
+	if !rt.isPtr {
+		instance = instance.Elem()
+	}
+	if e != nil {
+		e.Conversion = fmt.Sprintf("%v -> %s", value, instance.Type())
+	}
+	if !instance.Type().AssignableTo(fv.Type()) {
+		return resultFailed, fmt.Errorf("type %s is not assignable to field '%s' (%s): %w", instance.Type(), path, fv.Type(), ErrAssignError)
+	}
+	fv.Set(instance)
+	return resultSet, nil
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return prefix + "." + path
 }
@@ -55,10 +55,10 @@ func Test_decode(t *testing.T) {
 					String string `map:"string"`
 				}
 				target := Target{}
-				v, err := newParser(&d, []string{"map"}, &target)
+				v, err := newParser(&d, []string{"map"}, &target, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = decode(&target, v, &d)
+				err = decode(&target, v, &decodeState{decoder: &d})
 				assert.NoError(t, err)
 				assert.Equal(t, 12345, target.Int)
 				assert.Equal(t, "string", target.String)
@@ -72,10 +72,10 @@ func Test_decode(t *testing.T) {
 					String string `map:"not-found"` // if tag is not found, test decoder returns Skip
 				}
 				target := Target{}
-				v, err := newParser(&d, []string{"map"}, &target)
+				v, err := newParser(&d, []string{"map"}, &target, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = decode(&target, v, &d)
+				err = decode(&target, v, &decodeState{decoder: &d})
 				assert.NoError(t, err)
 				assert.Equal(t, 0, target.Int)
 				assert.Equal(t, "", target.String)
@@ -89,10 +89,10 @@ func Test_decode(t *testing.T) {
 					string string `map:"string"` // this is private
 				}
 				target := Target{}
-				v, err := newParser(&d, []string{"map"}, &target)
+				v, err := newParser(&d, []string{"map"}, &target, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = decode(&target, v, &d)
+				err = decode(&target, v, &decodeState{decoder: &d})
 				assert.NoError(t, err)
 				assert.Equal(t, 0, target.int)
 				assert.Equal(t, "", target.string)
@@ -106,10 +106,10 @@ func Test_decode(t *testing.T) {
 					Error  error         `map:"interface"`
 				}
 				target := Target{}
-				v, err := newParser(&d, []string{"map"}, &target)
+				v, err := newParser(&d, []string{"map"}, &target, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = decode(&target, v, &d)
+				err = decode(&target, v, &decodeState{decoder: &d})
 				assert.NoError(t, err)
 				assert.NotNil(t, target.Sample)
 				assert.Equal(t, "struct sample", target.Sample.Value)
@@ -127,10 +127,10 @@ func Test_decode(t *testing.T) {
 					String String `map:"string"`
 				}
 				target := Target{}
-				v, err := newParser(&d, []string{"map"}, &target)
+				v, err := newParser(&d, []string{"map"}, &target, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = decode(&target, v, &d)
+				err = decode(&target, v, &decodeState{decoder: &d})
 				assert.NoError(t, err)
 				assert.Equal(t, Int(12345), target.Int)
 				assert.Equal(t, String("string"), target.String)
@@ -143,3 +143,111 @@ func Test_decode(t *testing.T) {
 		})
 	}
 }
+
+type defaultDecoder struct {
+	mapDecoder
+	defaults map[string]any
+}
+
+func (d defaultDecoder) ExtractValue(tag any) (any, error) {
+	v, err := d.mapDecoder.ExtractValue(tag)
+	if err == Skip {
+		if dv, ok := d.defaults[tag.(string)]; ok {
+			return Default(dv), nil
+		}
+	}
+	return v, err
+}
+
+func TestDecodeWithResult(t *testing.T) {
+	d := defaultDecoder{
+		mapDecoder: mapDecoder{
+			values: map[string]any{
+				"int":    12345,
+				"string": "string",
+				"zero":   0,
+				"bad":    "not a number",
+			},
+		},
+		defaults: map[string]any{
+			"default": 10,
+		},
+	}
+	type Child struct {
+		String string `map:"string"`
+	}
+	type Target struct {
+		Int     int `map:"int"`
+		Zero    int `map:"zero"`
+		Missing int `map:"missing"`
+		Default int `map:"default"`
+		Bad     int `map:"bad"`
+		Child   Child
+	}
+	target := Target{}
+	result, err := DecodeWithResult(&target, []string{"map"}, &d)
+	assert.Error(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, []string{"Int", "Zero", "Child.String"}, result.Set)
+	assert.Equal(t, []string{"Missing"}, result.Skipped)
+	assert.Equal(t, []string{"Default"}, result.Defaulted)
+	assert.Equal(t, []string{"Bad"}, result.Failed)
+	assert.True(t, result.IsSet("Zero"))
+	assert.False(t, result.IsSet("Missing"))
+	assert.True(t, result.IsSkipped("Missing"))
+	assert.True(t, result.IsDefaulted("Default"))
+	assert.True(t, result.IsFailed("Bad"))
+
+	assert.Equal(t, 12345, target.Int)
+	assert.Equal(t, 10, target.Default)
+	assert.Equal(t, "string", target.Child.String)
+}
+
+type benchmarkFlat struct {
+	A string `map:"a"`
+	B string `map:"b"`
+	C string `map:"c"`
+	D string `map:"d"`
+}
+
+type benchmarkNested struct {
+	L1 struct {
+		L2 struct {
+			L3 struct {
+				L4 struct {
+					A string `map:"a"`
+					B string `map:"b"`
+					C string `map:"c"`
+					D string `map:"d"`
+				}
+			}
+		}
+	}
+}
+
+var benchmarkValues = map[string]any{"a": "a", "b": "b", "c": "c", "d": "d"}
+
+func BenchmarkDecode_Flat(b *testing.B) {
+	d := &mapDecoder{values: benchmarkValues}
+	var s benchmarkFlat
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Decode(&s, []string{"map"}, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode_Nested(b *testing.B) {
+	d := &mapDecoder{values: benchmarkValues}
+	var s benchmarkNested
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Decode(&s, []string{"map"}, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if s.L1.L2.L3.L4.D != "d" {
+		b.Fatal("nested field is not decoded")
+	}
+}
@@ -0,0 +1,134 @@
+package runtimescan
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// OpKind is a kind of operation that runtimescan does for each field.
+type OpKind string
+
+const (
+	// FieldOp means the field is passed to ExtractValue() or VisitField().
+	FieldOp OpKind = "field"
+	// ChildOp means the field is a struct and runtimescan traverses its fields.
+	ChildOp OpKind = "child"
+)
+
+// FieldDescription is an information of each field that runtimescan compiled.
+type FieldDescription struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	TagKey string `json:"tagKey,omitempty"`
+	RawTag string `json:"rawTag,omitempty"`
+	// Tag is a value that ParseTag() returned.
+	Tag          any                 `json:"tag,omitempty"`
+	Op           OpKind              `json:"op,omitempty"`
+	Embedded     bool                `json:"embedded,omitempty"`
+	Skipped      bool                `json:"skipped,omitempty"`
+	SkipTraverse bool                `json:"skipTraverse,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	Children     []*FieldDescription `json:"children,omitempty"`
+}
+
+// Description is a field tree that Describe() returns.
+type Description struct {
+	Type   string              `json:"type"`
+	Tags   []string            `json:"tags"`
+	Fields []*FieldDescription `json:"fields"`
+}
+
+// Describe returns the field tree that runtimescan compiles from sample struct and parser.
+//
+// It is for debugging tag grammars. Even if ParseTag() returns errors, it returns the tree
+// with the errors in FieldDescription.Error in addition to the error.
+func Describe(sample any, tags []string, vi Parser) (*Description, error) {
+	err := shouldPointerOfStruct(sample)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(sample).Elem()
+	p := &parser{
+		describe: true,
+	}
+	p.parse(vi, tags, t)
+	result := &Description{
+		Type:   t.String(),
+		Tags:   tags,
+		Fields: p.descriptions,
+	}
+	if len(p.errors) > 0 {
+		return result, &Errors{Errors: p.errors}
+	}
+	return result, nil
+}
+
+// String renders the field tree as a text table.
+func (d Description) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tTYPE\tOP\tTAG KEY\tRAW TAG\tPARSED TAG\tSTATE")
+	var write func(fields []*FieldDescription, depth int)
+	write = func(fields []*FieldDescription, depth int) {
+		for _, f := range fields {
+			var tag string
+			if f.Tag != nil {
+				tag = fmt.Sprintf("%+v", f.Tag)
+			}
+			fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\t%s\n", strings.Repeat("  ", depth), f.Path, f.Type, f.Op, f.TagKey, f.RawTag, tag, f.state())
+			write(f.Children, depth+1)
+		}
+	}
+	write(d.Fields, 0)
+	w.Flush()
+	return fmt.Sprintf("%s (tags: %s)\n%s", d.Type, strings.Join(d.Tags, ", "), trimTable(b.String()))
+}
+
+// trimTable removes trailing spaces that tabwriter adds to the last column.
+func trimTable(table string) string {
+	lines := strings.Split(table, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (f FieldDescription) state() string {
+	var states []string
+	if f.Embedded {
+		states = append(states, "embedded")
+	}
+	if f.Skipped {
+		states = append(states, "skipped")
+	}
+	if f.SkipTraverse {
+		states = append(states, "skip-traverse")
+	}
+	if f.Error != "" {
+		states = append(states, "error: "+f.Error)
+	}
+	return strings.Join(states, ", ")
+}
+
+// JSON renders the field tree as an indented JSON.
+//
+// If parsed tag value can't be marshaled, its fmt "%+v" representation is used instead.
+func (d Description) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f FieldDescription) MarshalJSON() ([]byte, error) {
+	type alias FieldDescription
+	a := alias(f)
+	if a.Tag != nil {
+		if _, err := json.Marshal(a.Tag); err != nil {
+			a.Tag = fmt.Sprintf("%+v", a.Tag)
+		}
+	}
+	return json.Marshal(a)
+}
@@ -0,0 +1,108 @@
+package runtimescan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type describeParser struct{}
+
+func (p describeParser) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	switch tagStr {
+	case "-":
+		return nil, Skip
+	case "opaque":
+		return nil, SkipTraverse
+	case "error":
+		return nil, errors.New("invalid tag")
+	}
+	return tagStr, nil
+}
+
+func TestDescribe(t *testing.T) {
+	type Base struct {
+		ID int `map:"id"`
+	}
+	type Child struct {
+		Value string `map:"value"`
+	}
+	type Target struct {
+		Base
+		Name    string `map:"name"`
+		Ignore  int    `map:"-"`
+		Child   Child  `json:"child"`
+		Opaque  Child  `map:"opaque"`
+		private int
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		d, err := Describe(&Target{}, []string{"map", "json"}, &describeParser{})
+		assert.NoError(t, err)
+		assert.Equal(t, "runtimescan.Target", d.Type)
+		assert.Len(t, d.Fields, 5)
+
+		assert.Equal(t, "Base", d.Fields[0].Path)
+		assert.True(t, d.Fields[0].Embedded)
+		assert.Equal(t, ChildOp, d.Fields[0].Op)
+		assert.Equal(t, "Base.ID", d.Fields[0].Children[0].Path)
+		assert.Equal(t, "id", d.Fields[0].Children[0].Tag)
+
+		assert.Equal(t, FieldOp, d.Fields[1].Op)
+		assert.Equal(t, "map", d.Fields[1].TagKey)
+
+		assert.True(t, d.Fields[2].Skipped)
+
+		assert.Equal(t, "json", d.Fields[3].TagKey)
+		assert.Equal(t, "Child.Value", d.Fields[3].Children[0].Path)
+
+		assert.True(t, d.Fields[4].SkipTraverse)
+		assert.Equal(t, FieldOp, d.Fields[4].Op)
+		assert.Empty(t, d.Fields[4].Children)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		d, err := Describe(&Target{}, []string{"map", "json"}, &describeParser{})
+		assert.NoError(t, err)
+		want := `runtimescan.Target (tags: map, json)
+PATH           TYPE               OP     TAG KEY  RAW TAG  PARSED TAG  STATE
+Base           runtimescan.Base   child                                embedded
+  Base.ID      int                field  map      id       id
+Name           string             field  map      name     name
+Ignore         int                field  map      -                    skipped
+Child          runtimescan.Child  child  json     child    child
+  Child.Value  string             field  map      value    value
+Opaque         runtimescan.Child  field  map      opaque               skip-traverse
+`
+		assert.Equal(t, want, d.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		type Simple struct {
+			Name string `map:"name"`
+		}
+		d, err := Describe(&Simple{}, []string{"map"}, &describeParser{})
+		assert.NoError(t, err)
+		j, err := d.JSON()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "runtimescan.Simple",
+			"tags": ["map"],
+			"fields": [
+				{"path": "Name", "name": "Name", "type": "string", "tagKey": "map", "rawTag": "name", "tag": "name", "op": "field"}
+			]
+		}`, string(j))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		type Invalid struct {
+			Name string `map:"error"`
+		}
+		d, err := Describe(&Invalid{}, []string{"map"}, &describeParser{})
+		assert.Error(t, err)
+		assert.NotNil(t, d)
+		assert.Equal(t, "invalid tag", d.Fields[0].Error)
+	})
+}
@@ -0,0 +1,138 @@
+package runtimescan
+
+// JSONEmbedding applies encoding/json's rules to fields of embedded structs.
+//
+// By default, all fields of embedded structs are visited even if the outer struct has a field with the same name.
+// With this option, fields of embedded structs are promoted with the following rules. The name of the field
+// is the first part of the tag (before comma) or the field name if the tag is empty.
+//
+//   - The shallowest field wins.
+//   - If there are multiple fields at the same depth, the tagged one wins.
+//   - Otherwise these fields are ambiguous and all of them are dropped.
+//
+// An embedded struct that has a tag is handled as a named struct field. Its fields are nested under
+// the name instead of being promoted.
+func JSONEmbedding() Option {
+	return func(o *options) {
+		o.jsonEmbedding = true
+	}
+}
+
+type embeddingEntry struct {
+	// start and end are the range of ops. end is exclusive.
+	start, end int
+	depth      int
+	tagged     bool
+}
+
+type embeddingKey struct {
+	scope int
+	name  string
+}
+
+// resolveEmbedding returns a copy of the compiled parser that dominated fields are dropped.
+func (d *parser) resolveEmbedding() *parser {
+	type frame struct {
+		scope int
+		depth int
+		entry *embeddingEntry
+	}
+	var stack []frame
+	current := frame{scope: -1}
+	groups := make(map[embeddingKey][]*embeddingEntry)
+	var keys []embeddingKey
+	addEntry := func(name string, e *embeddingEntry) {
+		key := embeddingKey{scope: current.scope, name: name}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], e)
+	}
+	for i, op := range d.fieldOps {
+		switch op {
+		case visitFieldOp:
+			f := d.fields[i]
+			addEntry(f.name, &embeddingEntry{start: i, end: i + 1, depth: current.depth, tagged: f.tagged})
+		case visitChildOp:
+			c := d.children[i]
+			stack = append(stack, current)
+			if c.embedded && !c.tagged {
+				current = frame{scope: current.scope, depth: current.depth + 1}
+			} else {
+				// a tagged embedded struct is handled as a named struct field. Its hooks are still
+				// not called as child because Go promotes them to the outer struct.
+				e := &embeddingEntry{start: i, depth: current.depth, tagged: c.tagged}
+				addEntry(c.name, e)
+				current = frame{scope: i, entry: e}
+			}
+		case leaveChildOp:
+			if current.entry != nil {
+				current.entry.end = i + 1
+			}
+			current = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	drop := make([]bool, len(d.fieldOps))
+	for _, key := range keys {
+		entries := groups[key]
+		if len(entries) == 1 {
+			continue
+		}
+		minDepth := entries[0].depth
+		for _, e := range entries[1:] {
+			if e.depth < minDepth {
+				minDepth = e.depth
+			}
+		}
+		var dominant *embeddingEntry
+		var count, tagged int
+		for _, e := range entries {
+			if e.depth != minDepth {
+				continue
+			}
+			count++
+			if e.tagged {
+				tagged++
+				dominant = e
+			}
+		}
+		if count == 1 {
+			for _, e := range entries {
+				if e.depth == minDepth {
+					dominant = e
+				}
+			}
+		} else if tagged != 1 {
+			dominant = nil
+		}
+		for _, e := range entries {
+			if e == dominant {
+				continue
+			}
+			for j := e.start; j < e.end; j++ {
+				drop[j] = true
+			}
+		}
+	}
+
+	result := &parser{
+		tags:       d.tags,
+		tagKey:     d.tagKey,
+		unexported: d.unexported,
+		skipped:    d.skipped[:len(d.skipped):len(d.skipped)],
+	}
+	for i, op := range d.fieldOps {
+		if drop[i] {
+			if f := d.fields[i]; op == visitFieldOp {
+				result.skipped = append(result.skipped, &skippedField{path: f.path, tag: f.tag, reason: "hidden by embedding rules"})
+			}
+			continue
+		}
+		result.fieldOps = append(result.fieldOps, op)
+		result.fields = append(result.fields, d.fields[i])
+		result.children = append(result.children, d.children[i])
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package runtimescan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pathEncoder records paths of visited fields.
+type pathEncoder struct {
+	paths []string
+}
+
+func (e *pathEncoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	return pathStr, nil
+}
+
+func (e *pathEncoder) VisitField(tag, value any) error {
+	e.paths = append(e.paths, tag.(string))
+	return nil
+}
+
+func (e *pathEncoder) EnterChild(tag any) error {
+	return nil
+}
+
+func (e *pathEncoder) LeaveChild(tag any) error {
+	return nil
+}
+
+type embeddingBase struct {
+	ID   string `map:"id"`
+	Name string `map:"name"`
+}
+
+type embeddingOther struct {
+	ID    string `map:"id"`
+	Title string
+}
+
+type embeddingTagged struct {
+	Title string `map:"Title"`
+}
+
+type EmbeddedBase struct {
+	ID   string `map:"id"`
+	Name string `map:"name"`
+}
+
+type EmbeddedLabel string
+
+func TestJSONEmbedding(t *testing.T) {
+	visit := func(t *testing.T, src any, opts ...Option) []string {
+		t.Helper()
+		e := &pathEncoder{}
+		err := Encode(src, []string{"map"}, e, opts...)
+		assert.NoError(t, err)
+		return e.paths
+	}
+
+	t.Run("shallowest wins", func(t *testing.T) {
+		type Outer struct {
+			embeddingBase
+			Name string `map:"name"`
+		}
+		assert.Equal(t, []string{"embeddingBase.ID", "embeddingBase.Name", "Name"}, visit(t, &Outer{}))
+		assert.Equal(t, []string{"embeddingBase.ID", "Name"}, visit(t, &Outer{}, JSONEmbedding()))
+	})
+
+	t.Run("ambiguous fields are dropped", func(t *testing.T) {
+		type Outer struct {
+			embeddingBase
+			embeddingOther
+		}
+		assert.Equal(t, []string{"embeddingBase.Name", "embeddingOther.Title"}, visit(t, &Outer{}, JSONEmbedding()))
+	})
+
+	t.Run("tagged beats untagged", func(t *testing.T) {
+		type Outer struct {
+			embeddingOther
+			embeddingTagged
+		}
+		assert.Equal(t, []string{"embeddingOther.ID", "embeddingTagged.Title"}, visit(t, &Outer{}, JSONEmbedding()))
+	})
+
+	t.Run("tagged embedded struct is nested", func(t *testing.T) {
+		type Outer struct {
+			embeddingBase `map:"base"`
+			ID            string `map:"id"`
+		}
+		assert.Equal(t, []string{"embeddingBase.ID", "embeddingBase.Name", "ID"}, visit(t, &Outer{}, JSONEmbedding()))
+	})
+
+	t.Run("field mask", func(t *testing.T) {
+		type Outer struct {
+			embeddingBase
+			Name string `map:"name"`
+		}
+		assert.Equal(t, []string{"Name"}, visit(t, &Outer{}, JSONEmbedding(), Exclude("embeddingBase")))
+	})
+}
+
+func TestEmbeddedPointer(t *testing.T) {
+	type Outer struct {
+		*EmbeddedBase
+		EmbeddedLabel `map:"label"`
+		Title         string `map:"title"`
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		d := &mapDecoder{values: map[string]any{"id": "1", "label": "new", "title": "hello"}}
+		var target Outer
+		err := Decode(&target, []string{"map"}, d)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", target.ID)
+		assert.Equal(t, EmbeddedLabel("new"), target.EmbeddedLabel)
+		assert.Equal(t, "hello", target.Title)
+	})
+
+	t.Run("unexported pointer", func(t *testing.T) {
+		type Private struct {
+			*embeddingBase
+		}
+		d := &mapDecoder{values: map[string]any{"id": "1"}}
+		var target Private
+		err := Decode(&target, []string{"map"}, d)
+		assert.ErrorIs(t, err.(*Errors).Errors[0], ErrAssignError)
+
+		err = Decode(&target, []string{"map"}, &mapDecoder{})
+		assert.NoError(t, err)
+		assert.Nil(t, target.embeddingBase)
+	})
+
+	t.Run("allocate only when assigned", func(t *testing.T) {
+		d := &mapDecoder{values: map[string]any{"title": "hello"}}
+		var target Outer
+		result, err := DecodeWithResult(&target, []string{"map"}, d)
+		assert.NoError(t, err)
+		assert.Nil(t, target.EmbeddedBase)
+		assert.Equal(t, []string{"EmbeddedBase.ID", "EmbeddedBase.Name", "EmbeddedLabel"}, result.Skipped)
+
+		d = &mapDecoder{values: map[string]any{"name": "alice"}}
+		err = Decode(&target, []string{"map"}, d)
+		assert.NoError(t, err)
+		assert.Equal(t, &EmbeddedBase{Name: "alice"}, target.EmbeddedBase)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		m := mapEncoder{result: map[string]any{}}
+		err := Encode(&Outer{Title: "hello"}, []string{"map"}, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"label": EmbeddedLabel(""), "title": "hello"}, m.result)
+
+		m = mapEncoder{result: map[string]any{}}
+		err = Encode(&Outer{EmbeddedBase: &EmbeddedBase{ID: "1"}}, []string{"map"}, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "1", "name": "", "label": EmbeddedLabel(""), "title": ""}, m.result)
+	})
+}
@@ -1,59 +1,194 @@
 package runtimescan
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 )
 
 // Encode convert from some source into struct by using tag information.
-func Encode(src any, tags []string, encoder Encoder) error {
-	v, err := getParser(src, tags, encoder)
+func Encode(src any, tags []string, encoder Encoder, opts ...Option) error {
+	o := newOptions(opts)
+	v, err := getProgram(src, tags, encoder, o, false)
 	if err != nil {
 		return err
 	}
-	return encode(encoder, v, src)
+	return encode(encoder, v, src, o)
 }
 
-func encode(encoder Encoder, v *parser, src any) error {
-	current := reflect.ValueOf(src).Elem()
-	stack := []reflect.Value{current}
-	var errors []error
+type encodeState struct {
+	encoder Encoder
+	visitor FieldVisitor
+	opts    *options
+	errors  []error
+}
+
+func encode(encoder Encoder, v *parser, src any, o *options) error {
+	if o == nil {
+		o = &options{}
+	}
+	s := &encodeState{encoder: encoder, opts: o}
+	s.visitor, _ = encoder.(FieldVisitor)
+	s.encodeStruct(reflect.ValueOf(src).Elem(), v, "")
+	if len(s.errors) > 0 {
+		return &Errors{
+			Errors: s.errors,
+		}
+	}
+	return nil
+}
+
+func (s *encodeState) addError(err error) {
+	if err != nil {
+		s.errors = append(s.errors, err)
+	}
+}
+
+// encodeStruct visits fields of the struct. prefix is a path of the struct when it is encoded as
+// a concrete type of polymorphic interface field.
+func (s *encodeState) encodeStruct(root reflect.Value, v *parser, prefix string) {
+	s.addError(callHook(root, hasBeforeEncode, v.tagKey))
+	if s.opts.tracer != nil {
+		traceSkipped(s.opts.tracer, TraceEncode, v, prefix)
+	}
 	for i, op := range v.fieldOps {
-		index := v.fieldIndexes[i]
 		field := v.fields[i]
 		switch op {
 		case visitFieldOp:
-			fv := current.Field(index)
-			var value any
-			if field.isPtr {
-				if fv.IsNil() {
-					value = nil
-				} else {
-					value = fv.Elem().Interface()
-				}
+			fv, ok := field.read(root, s.opts.unsafeAssign)
+			path := joinPath(prefix, field.path)
+			var e *TraceEvent
+			var start time.Time
+			if s.opts.tracer != nil {
+				e = &TraceEvent{Op: TraceEncode, Path: path, Tag: field.tag}
+				start = time.Now()
+			}
+			var status TraceStatus
+			var err error
+			if ok && !fv.IsValid() {
+				status, err = s.encodeMissing(field, e, "in nil embedded pointer")
+			} else if !ok {
+				status, err = s.encodeMissing(field, e, "unexported value can't be copied without UnsafeAssignUnexported()")
+			} else if field.hasKind {
+				status, err = s.encodePolymorphic(fv, v, field, path, e)
 			} else {
-				value = fv.Interface()
+				status, err = s.encodeField(fv, v, field, e)
 			}
-			err := encoder.VisitField(field.tag, value)
-			if err == Skip {
-				continue
-			} else if err != nil {
-				errors = append(errors, err)
-				continue
+			s.addError(err)
+			if e != nil {
+				e.Status = status
+				e.Err = err
+				e.Elapsed = time.Since(start)
+				s.opts.tracer.TraceField(e)
 			}
 		case visitChildOp:
 			// todo: call EnterChild
-			current = current.Field(index)
-			stack = append(stack, current)
+			s.addError(callChildHook(root, v.children[i], hasBeforeEncode, v.tagKey, false))
 		case leaveChildOp:
 			// todo: call LeaveChild
-			stack = stack[:len(stack)-1]
-			current = stack[len(stack)-1]
+			s.addError(callChildHook(root, v.children[i], hasAfterEncode, v.tagKey, false))
 		}
 	}
-	if len(errors) > 0 {
-		return &Errors{
-			Errors: errors,
+	s.addError(callHook(root, hasAfterEncode, v.tagKey))
+}
+
+// encodeField passes the value of the field to VisitField(). e is filled when tracing.
+func (s *encodeState) encodeField(fv reflect.Value, v *parser, field *field, e *TraceEvent) (TraceStatus, error) {
+	if s.opts.omitEmpty && field.omitEmpty && isEmptyValue(fv) {
+		if e != nil {
+			e.SkipReason = "omitempty"
 		}
+		return TraceSkipped, nil
 	}
-	return nil
+	value, err := fieldValue(fv, field, v.tagKey)
+	if err != nil {
+		return TraceFailed, err
+	}
+	if e != nil {
+		e.Value = value
+		if field.encodable {
+			e.Conversion = "EncodeTag"
+		}
+	}
+	if s.visitor != nil {
+		err = s.visitor.VisitFieldValue(field.tag, newFieldValue(fv, value))
+	} else {
+		err = s.encoder.VisitField(field.tag, value)
+	}
+	if err == Skip {
+		if e != nil {
+			e.SkipReason = "VisitField returned Skip"
+		}
+		return TraceSkipped, nil
+	} else if err != nil {
+		return TraceFailed, err
+	}
+	return TraceVisited, nil
+}
+
+// encodeMissing handles the field that doesn't have a value. It is skipped, but visited as nil by
+// EncodeAll() with HeaderEncoder to keep the fields of all records same as the header.
+func (s *encodeState) encodeMissing(field *field, e *TraceEvent, reason string) (TraceStatus, error) {
+	if !s.opts.visitMissing {
+		if e != nil {
+			e.SkipReason = reason
+		}
+		return TraceSkipped, nil
+	}
+	var err error
+	if s.visitor != nil {
+		err = s.visitor.VisitFieldValue(field.tag, FieldValue{IsNil: true, IsZero: true})
+	} else {
+		err = s.encoder.VisitField(field.tag, nil)
+	}
+	if err == Skip {
+		if e != nil {
+			e.SkipReason = "VisitField returned Skip"
+		}
+		return TraceSkipped, nil
+	} else if err != nil {
+		return TraceFailed, err
+	}
+	return TraceVisited, nil
+}
+
+// encodePolymorphic visits the discriminator and the fields of the concrete type in the interface field.
+// The discriminator is passed through encodeField() as a string field, so FieldVisitor and OmitEmpty() work for it too.
+// Fields of the concrete type are reported by the nested encodeStruct(). e is filled when tracing.
+func (s *encodeState) encodePolymorphic(fv reflect.Value, v *parser, field *field, path string, e *TraceEvent) (TraceStatus, error) {
+	kf := field.discriminator()
+	if fv.IsNil() {
+		// nil interface is passed as nil *string
+		kf.isPtr = true
+		return s.encodeField(reflect.Zero(kf.fieldType()), v, kf, e)
+	}
+	if s.opts.registry == nil {
+		return TraceFailed, fmt.Errorf("field '%s' has '%s' tag, but TypeRegistry is not passed", path, DiscriminatorTag)
+	}
+	concrete := fv.Elem()
+	name, ok := s.opts.registry.Name(concrete.Interface())
+	if !ok {
+		return TraceFailed, fmt.Errorf("type %s of field '%s' is not registered", concrete.Type(), path)
+	}
+	status, err := s.encodeField(reflect.ValueOf(name), v, kf, e)
+	if err != nil {
+		return status, err
+	}
+	if e != nil {
+		e.Conversion = fmt.Sprintf("%s -> %s", concrete.Type(), name)
+	}
+	var ptr reflect.Value
+	if concrete.Kind() == reflect.Pointer {
+		ptr = concrete
+	} else {
+		// copy to make it addressable
+		ptr = reflect.New(concrete.Type())
+		ptr.Elem().Set(concrete)
+	}
+	cv, err := getParser(ptr.Interface(), v.tags, s.encoder, v.unexported)
+	if err != nil {
+		return TraceFailed, err
+	}
+	s.encodeStruct(ptr.Elem(), cv, path)
+	return status, nil
 }
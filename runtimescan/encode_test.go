@@ -53,10 +53,10 @@ func Test_encode(t *testing.T) {
 					PtrInt:    &[]int{10}[0],
 					PtrString: nil,
 				}
-				v, err := newParser(&m, []string{"map"}, &source)
+				v, err := newParser(&m, []string{"map"}, &source, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = encode(&m, v, &source)
+				err = encode(&m, v, &source, nil)
 				assert.NoError(t, err)
 				assert.Equal(t, 12345, m.result["int"])
 				assert.Equal(t, "test string", m.result["string"])
@@ -83,10 +83,10 @@ func Test_encode(t *testing.T) {
 						String: "test string",
 					},
 				}
-				v, err := newParser(&m, []string{"map"}, &source)
+				v, err := newParser(&m, []string{"map"}, &source, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = encode(&m, v, &source)
+				err = encode(&m, v, &source, nil)
 				assert.NoError(t, err)
 				assert.Equal(t, 12345, m.result["int"])
 				assert.Equal(t, "test string", m.result["string"])
@@ -112,10 +112,10 @@ func Test_encode(t *testing.T) {
 					PtrInt:    &[]Int{10}[0],
 					PtrString: nil,
 				}
-				v, err := newParser(&m, []string{"map"}, &source)
+				v, err := newParser(&m, []string{"map"}, &source, false)
 				assert.NoError(t, err)
 				assert.NotNil(t, v)
-				err = encode(&m, v, &source)
+				err = encode(&m, v, &source, nil)
 				assert.NoError(t, err)
 				assert.Equal(t, Int(12345), m.result["int"])
 				assert.Equal(t, String("test string"), m.result["string"])
@@ -0,0 +1,77 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// FieldValue is a value of the field with its zero-value information.
+type FieldValue struct {
+	// Value is as same as the value that VisitField() receives. Pointers are dereferenced and nil pointer is nil.
+	Value any
+	// Original is the field value as is. Pointers are not dereferenced.
+	Original any
+	// IsPtr is true if the field is a pointer.
+	IsPtr bool
+	// IsNil is true if the field is a nil pointer, map, slice, interface, chan or func.
+	IsNil bool
+	// IsZero is true if the field is the zero value of its type. A non-nil pointer to zero value is not zero.
+	IsZero bool
+}
+
+// FieldVisitor is an optional interface of Encoder.
+//
+// If the encoder implements it, Encode() calls VisitFieldValue() instead of VisitField(). It can distinguish
+// a nil pointer field from a non-nil pointer to zero value without re-implementing zero checks.
+type FieldVisitor interface {
+	VisitFieldValue(tag any, value FieldValue) (err error)
+}
+
+// OmitEmpty makes Encode() skip fields that have "omitempty" option in the tag (like `map:"name,omitempty"`)
+// and have empty value.
+//
+// The definition of empty is as same as encoding/json: false, 0, nil pointer, nil interface and
+// empty array, slice, map or string.
+func OmitEmpty() Option {
+	return func(o *options) {
+		o.omitEmpty = true
+	}
+}
+
+func newFieldValue(fv reflect.Value, value any) FieldValue {
+	result := FieldValue{
+		Value:    value,
+		Original: fv.Interface(),
+		IsPtr:    fv.Kind() == reflect.Ptr,
+		IsZero:   fv.IsZero(),
+	}
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func:
+		result.IsNil = fv.IsNil()
+	}
+	return result
+}
+
+// hasOmitEmpty returns true if the tag has "omitempty" option.
+func hasOmitEmpty(tagStr string) bool {
+	o, err := ParseOptions(tagStr)
+	return err == nil && o.Has("omitempty")
+}
+
+// isEmptyValue is as same as encoding/json's.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
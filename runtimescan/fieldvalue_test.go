@@ -0,0 +1,105 @@
+package runtimescan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldValueEncoder struct {
+	values map[string]FieldValue
+}
+
+func (e *fieldValueEncoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	return pathStr, nil
+}
+
+func (e *fieldValueEncoder) VisitField(tag, value any) error {
+	panic("VisitFieldValue should be called")
+}
+
+func (e *fieldValueEncoder) VisitFieldValue(tag any, value FieldValue) error {
+	e.values[tag.(string)] = value
+	return nil
+}
+
+func (e *fieldValueEncoder) EnterChild(tag any) error {
+	return nil
+}
+
+func (e *fieldValueEncoder) LeaveChild(tag any) error {
+	return nil
+}
+
+func TestFieldVisitor(t *testing.T) {
+	type Source struct {
+		Count    int
+		NilPtr   *int
+		ZeroPtr  *int
+		Tags     []string
+		Empty    []string
+		Name     string
+		Children map[string]int
+	}
+	zero := 0
+	e := &fieldValueEncoder{values: map[string]FieldValue{}}
+	err := Encode(&Source{ZeroPtr: &zero, Empty: []string{}, Name: "alice"}, []string{"map"}, e)
+	assert.NoError(t, err)
+
+	assert.Equal(t, FieldValue{Value: 0, Original: 0, IsZero: true}, e.values["Count"])
+	assert.Equal(t, FieldValue{Value: nil, Original: (*int)(nil), IsPtr: true, IsNil: true, IsZero: true}, e.values["NilPtr"])
+	assert.Equal(t, FieldValue{Value: 0, Original: &zero, IsPtr: true}, e.values["ZeroPtr"])
+	assert.True(t, e.values["Tags"].IsNil)
+	assert.False(t, e.values["Empty"].IsNil)
+	assert.False(t, e.values["Empty"].IsZero)
+	assert.False(t, e.values["Name"].IsZero)
+	assert.True(t, e.values["Children"].IsNil)
+}
+
+func TestOmitEmpty(t *testing.T) {
+	type Source struct {
+		Name    string   `map:"name,omitempty"`
+		Age     int      `map:"age,omitempty"`
+		Score   *int     `map:"score,omitempty"`
+		Tags    []string `map:"tags,omitempty"`
+		Enabled bool     `map:"enabled"`
+	}
+	zero := 0
+	encode := func(src *Source, opts ...Option) map[string]any {
+		m := &omitEncoder{result: map[string]any{}}
+		err := Encode(src, []string{"map"}, m, opts...)
+		assert.NoError(t, err)
+		return m.result
+	}
+
+	assert.Equal(t, map[string]any{"name": "", "age": 0, "score": nil, "tags": []string(nil), "enabled": false}, encode(&Source{}))
+	assert.Equal(t, map[string]any{"enabled": false}, encode(&Source{Tags: []string{}}, OmitEmpty()))
+	assert.Equal(t, map[string]any{"name": "alice", "score": 0, "enabled": true}, encode(&Source{Name: "alice", Score: &zero, Enabled: true}, OmitEmpty()))
+}
+
+// omitEncoder uses the name part of the tag as a key.
+type omitEncoder struct {
+	result map[string]any
+}
+
+func (m *omitEncoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	o, err := ParseOptions(tagStr)
+	if err != nil {
+		return nil, err
+	}
+	return o.Name, nil
+}
+
+func (m *omitEncoder) VisitField(tag, value any) error {
+	m.result[tag.(string)] = value
+	return nil
+}
+
+func (m *omitEncoder) EnterChild(tag any) error {
+	return nil
+}
+
+func (m *omitEncoder) LeaveChild(tag any) error {
+	return nil
+}
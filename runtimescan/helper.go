@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
 // BasicTag is struct for convenience.
@@ -23,17 +22,9 @@ type BasicTag struct {
 //
 // Both Encoder and Decoder should implement ParseTag() method.
 // This is the simplest implementation of these methods.
+// Use Naming's BasicParseTag() method to select other naming strategy than LowerCase.
 func BasicParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (tag any, err error) {
-	if tagStr == "" {
-		tagStr = strings.ToLower(name)
-	}
-	return &BasicTag{
-		Name:     name,
-		TagKey:   tagKey,
-		Tag:      tagStr,
-		Path:     pathStr,
-		ElemType: elemType,
-	}, nil
+	return LowerCase.BasicParseTag(name, tagKey, tagStr, pathStr, elemType)
 }
 
 // Str2PrimitiveValue is a helper function that generates primitive from string like "1", "true".
@@ -0,0 +1,106 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// BeforeDecoder is implemented by destination structs (and nested child structs) that want to be
+// notified before Decode() assigns their fields.
+//
+// tagKey is the first tag key passed to Decode().
+type BeforeDecoder interface {
+	BeforeDecode(tagKey string) error
+}
+
+// AfterDecoder is implemented by destination structs (and nested child structs) that want to
+// normalize values or derive computed fields after Decode() assigns their fields.
+type AfterDecoder interface {
+	AfterDecode(tagKey string) error
+}
+
+// BeforeEncoder is implemented by source structs (and nested child structs) that want to prepare
+// their fields before Encode() visits them.
+type BeforeEncoder interface {
+	BeforeEncode(tagKey string) error
+}
+
+// AfterEncoder is implemented by source structs (and nested child structs) that want to be
+// notified after Encode() visits their fields.
+type AfterEncoder interface {
+	AfterEncode(tagKey string) error
+}
+
+type hookFlags int
+
+const (
+	hasBeforeDecode hookFlags = 1 << iota
+	hasAfterDecode
+	hasBeforeEncode
+	hasAfterEncode
+)
+
+var (
+	beforeDecoderType = reflect.TypeOf((*BeforeDecoder)(nil)).Elem()
+	afterDecoderType  = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+	beforeEncoderType = reflect.TypeOf((*BeforeEncoder)(nil)).Elem()
+	afterEncoderType  = reflect.TypeOf((*AfterEncoder)(nil)).Elem()
+)
+
+// detectHooks checks hook methods of the struct type. Both value and pointer receivers are detected.
+func detectHooks(t reflect.Type) hookFlags {
+	pt := reflect.PointerTo(t)
+	var result hookFlags
+	if pt.Implements(beforeDecoderType) {
+		result |= hasBeforeDecode
+	}
+	if pt.Implements(afterDecoderType) {
+		result |= hasAfterDecode
+	}
+	if pt.Implements(beforeEncoderType) {
+		result |= hasBeforeEncode
+	}
+	if pt.Implements(afterEncoderType) {
+		result |= hasAfterEncode
+	}
+	return result
+}
+
+// callHook calls the hook method of the struct. v should be addressable struct value.
+func callHook(v reflect.Value, flag hookFlags, tagKey string) error {
+	i := v.Addr().Interface()
+	switch flag {
+	case hasBeforeDecode:
+		if h, ok := i.(BeforeDecoder); ok {
+			return h.BeforeDecode(tagKey)
+		}
+	case hasAfterDecode:
+		if h, ok := i.(AfterDecoder); ok {
+			return h.AfterDecode(tagKey)
+		}
+	case hasBeforeEncode:
+		if h, ok := i.(BeforeEncoder); ok {
+			return h.BeforeEncode(tagKey)
+		}
+	case hasAfterEncode:
+		if h, ok := i.(AfterEncoder); ok {
+			return h.AfterEncode(tagKey)
+		}
+	}
+	return nil
+}
+
+// callChildHook calls the hook method of the child struct. write is true for Decode().
+//
+// Hooks of embedded structs are not called as child because Go promotes them to the outer struct.
+// Hooks of child structs in nil embedded pointers are not called because they are not allocated.
+// Encode() doesn't call hooks of unexported child structs because it doesn't expose them.
+func callChildHook(root reflect.Value, c *child, flag hookFlags, tagKey string, write bool) error {
+	if c.embedded || c.hooks&flag == 0 || (c.unexported && !write) {
+		return nil
+	}
+	v, err := c.get(root, false)
+	if err != nil || !v.IsValid() {
+		return err
+	}
+	return callHook(v, flag, tagKey)
+}
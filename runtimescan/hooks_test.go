@@ -0,0 +1,125 @@
+package runtimescan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hookLog []string
+
+type hookAddress struct {
+	City string `map:"city"`
+}
+
+func (a *hookAddress) BeforeDecode(tagKey string) error {
+	hookLog = append(hookLog, "before decode address ("+tagKey+")")
+	return nil
+}
+
+func (a *hookAddress) AfterDecode(tagKey string) error {
+	hookLog = append(hookLog, "after decode address: "+a.City)
+	a.City = strings.TrimSpace(a.City)
+	return nil
+}
+
+type hookBase struct {
+	ID string `map:"id"`
+}
+
+func (b *hookBase) AfterDecode(tagKey string) error {
+	hookLog = append(hookLog, "after decode base")
+	return nil
+}
+
+type hookUser struct {
+	hookBase
+	Name     string      `map:"name"`
+	Address  hookAddress `map:"address"`
+	FullName string      `map:"-"`
+}
+
+func (u *hookUser) BeforeDecode(tagKey string) error {
+	hookLog = append(hookLog, "before decode user")
+	return nil
+}
+
+func (u *hookUser) AfterDecode(tagKey string) error {
+	hookLog = append(hookLog, "after decode user")
+	u.FullName = u.Name + " (" + u.Address.City + ")"
+	return nil
+}
+
+func (u *hookUser) BeforeEncode(tagKey string) error {
+	u.Name = strings.ToUpper(u.Name)
+	return nil
+}
+
+func (u hookUser) AfterEncode(tagKey string) error {
+	return errors.New("after encode error")
+}
+
+func TestHooks(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		hookLog = nil
+		d := mapDecoder{
+			values: map[string]any{
+				"id":   "1",
+				"name": "user",
+				"city": " Tokyo ",
+			},
+		}
+		u := hookUser{}
+		err := Decode(&u, []string{"map"}, &d)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"before decode user",
+			"before decode address (map)",
+			"after decode address:  Tokyo ",
+			"after decode user",
+		}, hookLog)
+		assert.Equal(t, "Tokyo", u.Address.City)
+		assert.Equal(t, "user (Tokyo)", u.FullName)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		e := mapEncoder{
+			result: make(map[string]any),
+		}
+		u := hookUser{Name: "user"}
+		err := Encode(&u, []string{"map"}, &e)
+		var errs *Errors
+		assert.True(t, errors.As(err, &errs))
+		assert.EqualError(t, errs.Errors[0], "after encode error")
+		assert.Equal(t, "USER", e.result["name"])
+	})
+
+	t.Run("embedded hook is promoted", func(t *testing.T) {
+		type Outer struct {
+			hookBase
+		}
+		hookLog = nil
+		o := Outer{}
+		err := Decode(&o, []string{"map"}, &mapDecoder{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"after decode base"}, hookLog)
+	})
+}
+
+type hookTaggedOuter struct {
+	hookBase `map:"base"`
+	Name     string `map:"name"`
+}
+
+func TestHooks_TaggedEmbedded(t *testing.T) {
+	hookLog = nil
+	d := &mapDecoder{values: map[string]any{"id": "1", "name": "alice"}}
+	var target hookTaggedOuter
+	err := Decode(&target, []string{"map"}, d, JSONEmbedding())
+	assert.NoError(t, err)
+	assert.Equal(t, "1", target.ID)
+	// promoted AfterDecode of hookBase is called once as the hook of the outer struct
+	assert.Equal(t, []string{"after decode base"}, hookLog)
+}
@@ -0,0 +1,185 @@
+package runtimescan
+
+import (
+	"fmt"
+	"strings"
+)
+
+type maskSegment struct {
+	name    string
+	indexes []string
+}
+
+type maskPattern []maskSegment
+
+// FieldMask is a set of include/exclude field path patterns.
+//
+// Pattern is a dot separated field path like "Address.City". "*" matches any field name
+// and "[*]" matches any slice index ("Items[*].Price"). A pattern matches the field and
+// all its descendants, so "Address" and "Address.*" include all fields of Address.
+//
+// runtimescan doesn't traverse slices, so a field that is an ancestor of include patterns
+// (Items of "Items[*].Price") is kept as is. Adapters that traverse slices by themselves
+// can use Match() with indexed paths like "Items[3].Price".
+type FieldMask struct {
+	include []maskPattern
+	exclude []maskPattern
+}
+
+// NewFieldMask parses patterns and creates FieldMask.
+//
+// If include is empty, all fields except exclude patterns are matched.
+func NewFieldMask(include, exclude []string) (*FieldMask, error) {
+	result := &FieldMask{}
+	for _, p := range include {
+		mp, err := parseMaskPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		result.include = append(result.include, mp)
+	}
+	for _, p := range exclude {
+		mp, err := parseMaskPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		result.exclude = append(result.exclude, mp)
+	}
+	return result, nil
+}
+
+func parseMaskPattern(pattern string) (maskPattern, error) {
+	return parseFieldPath(pattern, true)
+}
+
+func parseFieldPath(path string, allowWildcard bool) ([]maskSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("field path is empty: %w", ErrInvalidPath)
+	}
+	var result []maskSegment
+	for _, s := range strings.Split(path, ".") {
+		var seg maskSegment
+		i := strings.IndexByte(s, '[')
+		if i == -1 {
+			seg.name = s
+		} else {
+			seg.name = s[:i]
+			rest := s[i:]
+			for rest != "" {
+				if rest[0] != '[' {
+					return nil, fmt.Errorf("field path '%s' is invalid: unexpected '%s': %w", path, rest, ErrInvalidPath)
+				}
+				end := strings.IndexByte(rest, ']')
+				if end == -1 {
+					return nil, fmt.Errorf("field path '%s' is invalid: ']' is missing: %w", path, ErrInvalidPath)
+				}
+				index := rest[1:end]
+				if index == "" || (index == "*" && !allowWildcard) {
+					return nil, fmt.Errorf("field path '%s' is invalid: index '%s': %w", path, index, ErrInvalidPath)
+				}
+				seg.indexes = append(seg.indexes, index)
+				rest = rest[end+1:]
+			}
+		}
+		if seg.name == "" || (seg.name == "*" && !allowWildcard) {
+			return nil, fmt.Errorf("field path '%s' is invalid: empty or wildcard field name: %w", path, ErrInvalidPath)
+		}
+		result = append(result, seg)
+	}
+	return result, nil
+}
+
+type maskRelation int
+
+const (
+	maskUnmatch maskRelation = iota
+	// maskCover means the pattern matches the path or its ancestor.
+	maskCover
+	// maskAncestor means the path is an ancestor of the pattern.
+	maskAncestor
+)
+
+func (p maskPattern) relation(path []maskSegment) maskRelation {
+	for i, ps := range p {
+		if i >= len(path) {
+			return maskAncestor
+		}
+		s := path[i]
+		if ps.name != "*" && ps.name != s.name {
+			return maskUnmatch
+		}
+		for j, pi := range ps.indexes {
+			if j >= len(s.indexes) {
+				return maskAncestor
+			}
+			if pi != "*" && pi != s.indexes[j] {
+				return maskUnmatch
+			}
+		}
+	}
+	return maskCover
+}
+
+// Match returns true if the field path is a target of the mask.
+func (m FieldMask) Match(path string) bool {
+	segments, err := parseFieldPath(path, false)
+	if err != nil {
+		return false
+	}
+	return m.match(segments)
+}
+
+func (m FieldMask) match(path []maskSegment) bool {
+	for _, p := range m.exclude {
+		if p.relation(path) == maskCover {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, p := range m.include {
+		if p.relation(path) != maskUnmatch {
+			return true
+		}
+	}
+	return false
+}
+
+// prune returns a copy of the compiled parser that only has fields the mask matches.
+// Child structs that have no fields are removed.
+func (d *parser) prune(mask *FieldMask) *parser {
+	result := &parser{
+		tags:       d.tags,
+		tagKey:     d.tagKey,
+		unexported: d.unexported,
+		skipped:    d.skipped[:len(d.skipped):len(d.skipped)],
+	}
+	var starts []int
+	for i, op := range d.fieldOps {
+		switch op {
+		case visitFieldOp:
+			f := d.fields[i]
+			path, err := parseFieldPath(f.path, false)
+			if err != nil || !mask.match(path) {
+				result.skipped = append(result.skipped, &skippedField{path: f.path, tag: f.tag, reason: "excluded by mask"})
+				continue
+			}
+		case visitChildOp:
+			starts = append(starts, len(result.fieldOps))
+		case leaveChildOp:
+			start := starts[len(starts)-1]
+			starts = starts[:len(starts)-1]
+			if len(result.fieldOps) == start+1 {
+				result.fieldOps = result.fieldOps[:start]
+				result.fields = result.fields[:start]
+				result.children = result.children[:start]
+				continue
+			}
+		}
+		result.fieldOps = append(result.fieldOps, op)
+		result.fields = append(result.fields, d.fields[i])
+		result.children = append(result.children, d.children[i])
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package runtimescan
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMask_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		path    string
+		want    bool
+	}{
+		{name: "no patterns", path: "Name", want: true},
+		{name: "exact", include: []string{"Name"}, path: "Name", want: true},
+		{name: "other", include: []string{"Name"}, path: "Age", want: false},
+		{name: "descendant", include: []string{"Address"}, path: "Address.City", want: true},
+		{name: "wildcard", include: []string{"Address.*"}, path: "Address.City", want: true},
+		{name: "wildcard doesn't match parent sibling", include: []string{"Address.*"}, path: "Name", want: false},
+		{name: "ancestor of include", include: []string{"Address.City"}, path: "Address", want: true},
+		{name: "slice field is ancestor", include: []string{"Items[*].Price"}, path: "Items", want: true},
+		{name: "indexed path", include: []string{"Items[*].Price"}, path: "Items[3].Price", want: true},
+		{name: "indexed path (other field)", include: []string{"Items[*].Price"}, path: "Items[3].Name", want: false},
+		{name: "specific index", include: []string{"Items[1].Price"}, path: "Items[3].Price", want: false},
+		{name: "exclude", exclude: []string{"Password"}, path: "Password", want: false},
+		{name: "exclude descendant", exclude: []string{"Secret"}, path: "Secret.Key", want: false},
+		{name: "exclude has priority", include: []string{"User"}, exclude: []string{"User.Password"}, path: "User.Password", want: false},
+		{name: "exclude of child doesn't exclude parent", exclude: []string{"Items[*].Price"}, path: "Items", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewFieldMask(tt.include, tt.exclude)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, m.Match(tt.path))
+		})
+	}
+}
+
+func TestNewFieldMask_Error(t *testing.T) {
+	for _, pattern := range []string{"", "Items[", "Items[]", "Address..City", "Items[0]x"} {
+		t.Run(pattern, func(t *testing.T) {
+			_, err := NewFieldMask([]string{pattern}, nil)
+			assert.True(t, errors.Is(err, ErrInvalidPath))
+		})
+	}
+}
+
+func TestFieldMask_DecodeEncode(t *testing.T) {
+	type Address struct {
+		City    string `map:"city"`
+		Country string `map:"country"`
+	}
+	type Target struct {
+		Name     string  `map:"name"`
+		Password string  `map:"password"`
+		Address  Address `map:"address"`
+		Other    Address `map:"other"`
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"name":     "name",
+				"password": "secret",
+				"city":     "Tokyo",
+				"country":  "Japan",
+			},
+		}
+		target := Target{}
+		result, err := DecodeWithResult(&target, []string{"map"}, &d, Include("Name", "Password", "Address.*"), Exclude("Password", "Address.Country"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Address.City"}, result.Set)
+		assert.Equal(t, Target{Name: "name", Address: Address{City: "Tokyo"}}, target)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		e := mapEncoder{
+			result: make(map[string]any),
+		}
+		source := Target{
+			Name:     "name",
+			Password: "secret",
+			Other:    Address{City: "Osaka"},
+		}
+		err := Encode(&source, []string{"map"}, &e, Exclude("Password", "Address"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "name", "city": "Osaka", "country": ""}, e.result)
+	})
+
+	t.Run("pruned program", func(t *testing.T) {
+		v, err := newParser(&mapDecoder{}, []string{"map"}, &Target{}, false)
+		assert.NoError(t, err)
+		m, err := NewFieldMask([]string{"Other.City"}, nil)
+		assert.NoError(t, err)
+		pruned := v.prune(m)
+		assert.Equal(t, []visitOpType{visitChildOp, visitFieldOp, leaveChildOp}, pruned.fieldOps)
+		assert.Equal(t, []int{3, 0, -1}, opIndexes(pruned))
+		assert.Len(t, v.fieldOps, 10)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		err := Decode(&Target{}, []string{"map"}, &mapDecoder{}, Include("Items["))
+		assert.True(t, errors.Is(err, ErrInvalidPath))
+	})
+}
+
+func TestFieldMask_ProgramCache(t *testing.T) {
+	type Target struct {
+		Name     string `map:"name"`
+		Password string `map:"password"`
+	}
+	d := mapDecoder{}
+	p, err := getParser(&Target{}, []string{"map"}, &d, false)
+	assert.NoError(t, err)
+
+	o := newOptions([]Option{Exclude("Password")})
+	first, err := o.program(p)
+	assert.NoError(t, err)
+	second, err := newOptions([]Option{Exclude("Password")}).program(p)
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Len(t, first.fields, 1)
+
+	other, err := newOptions([]Option{Exclude("Name")}).program(p)
+	assert.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, "Password", other.fields[0].path)
+
+	t.Run("bounded", func(t *testing.T) {
+		for i := 0; i < maxPrograms+5; i++ {
+			_, err := newOptions([]Option{Exclude(fmt.Sprintf("Field%d", i))}).program(p)
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, maxPrograms, p.programs.len())
+		again, err := newOptions([]Option{Exclude("Password")}).program(p)
+		assert.NoError(t, err)
+		assert.NotSame(t, first, again, "evicted")
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		ResetCache()
+		defer ResetCache()
+		assert.NoError(t, Decode(&Target{}, []string{"map"}, &d, Exclude("Password")))
+		assert.NoError(t, Decode(&Target{}, []string{"map"}, &d, Exclude("Name")))
+		assert.Equal(t, 2, GetCacheStats().Programs)
+	})
+}
@@ -0,0 +1,69 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// TagValue is a pair of tag key and its value.
+type TagValue struct {
+	Key   string
+	Value string
+}
+
+// TagValues is an ordered map of tag keys and values of a field.
+//
+// It keeps tag keys in the order that is passed to Decode() or Encode().
+type TagValues []TagValue
+
+// Lookup returns the value of the tag key.
+func (t TagValues) Lookup(key string) (string, bool) {
+	for _, v := range t {
+		if v.Key == key {
+			return v.Value, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the value of the tag key. It returns empty string if the field doesn't have the tag key.
+func (t TagValues) Get(key string) string {
+	v, _ := t.Lookup(key)
+	return v
+}
+
+// Keys returns the tag keys in order.
+func (t TagValues) Keys() []string {
+	result := make([]string, 0, len(t))
+	for _, v := range t {
+		result = append(result, v.Key)
+	}
+	return result
+}
+
+// MergedTagParser is an optional interface of Parser.
+//
+// By default, ParseTag() receives only the first non-empty tag among the tag keys. If the parser implements
+// MergedTagParser, ParseTags() is called instead with all tag keys that the field has, so independent
+// concerns (source, default value, documents and so on) can be written in separate tag keys:
+//
+//	type Request struct {
+//		Limit int `rest:"query" default:"10" doc:"max number of items"`
+//	}
+//
+//	runtimescan.Decode(&req, []string{"rest", "default", "doc"}, decoder)
+//
+// Tag keys that the field has with an empty value are included. The result is cached as same as ParseTag().
+type MergedTagParser interface {
+	ParseTags(name string, tags TagValues, pathStr string, elemType reflect.Type) (tag any, err error)
+}
+
+// lookupTags returns all tag keys and values that the field has.
+func lookupTags(f reflect.StructField, tags []string) TagValues {
+	var result TagValues
+	for _, key := range tags {
+		if v, ok := f.Tag.Lookup(key); ok {
+			result = append(result, TagValue{Key: key, Value: v})
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package runtimescan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type queryTag struct {
+	name     string
+	fallback string
+	doc      string
+}
+
+// queryDecoder reads values from query map. Default values and documents are written in separate tag keys.
+type queryDecoder struct {
+	query map[string]string
+	tags  map[string]TagValues
+}
+
+func (d *queryDecoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	panic("ParseTags should be called")
+}
+
+func (d *queryDecoder) ParseTags(name string, tags TagValues, pathStr string, elemType reflect.Type) (any, error) {
+	d.tags[pathStr] = tags
+	q, ok := tags.Lookup("query")
+	if !ok {
+		return nil, Skip
+	}
+	if q == "" {
+		q = name
+	}
+	return &queryTag{name: q, fallback: tags.Get("default"), doc: tags.Get("doc")}, nil
+}
+
+func (d *queryDecoder) ExtractValue(tag any) (any, error) {
+	t := tag.(*queryTag)
+	if v, ok := d.query[t.name]; ok {
+		return v, nil
+	}
+	if t.fallback != "" {
+		return Default(t.fallback), nil
+	}
+	return nil, Skip
+}
+
+func TestMergedTagParser(t *testing.T) {
+	type Request struct {
+		Limit  int    `query:"limit" default:"10" doc:"max number of items"`
+		Offset int    `doc:"start position" query:"offset" default:"0"`
+		Sort   string `query:"" default:""`
+		Debug  bool
+	}
+	d := &queryDecoder{query: map[string]string{"offset": "20", "Sort": "name"}, tags: map[string]TagValues{}}
+	var req Request
+	result, err := DecodeWithResult(&req, []string{"query", "default", "doc"}, d)
+	assert.NoError(t, err)
+	assert.Equal(t, Request{Limit: 10, Offset: 20, Sort: "name"}, req)
+	assert.Equal(t, []string{"Limit"}, result.Defaulted)
+
+	assert.Equal(t, TagValues{
+		{Key: "query", Value: "offset"},
+		{Key: "default", Value: "0"},
+		{Key: "doc", Value: "start position"},
+	}, d.tags["Offset"])
+	assert.Equal(t, []string{"query", "default"}, d.tags["Sort"].Keys())
+	assert.Nil(t, d.tags["Debug"])
+}
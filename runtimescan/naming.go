@@ -0,0 +1,170 @@
+package runtimescan
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Naming is a strategy that creates a name from a field name for fields without tag.
+//
+// Parser implementations select one of them and use it via Convert() or BasicParseTag().
+type Naming int
+
+const (
+	// LowerCase converts "UserID" into "userid". It is the default of BasicParseTag().
+	LowerCase Naming = iota + 1
+	// SnakeCase converts "UserID" into "user_id".
+	SnakeCase
+	// KebabCase converts "UserID" into "user-id".
+	KebabCase
+	// CamelCase converts "UserID" into "userId".
+	CamelCase
+	// PascalCase converts "UserID" into "UserId".
+	PascalCase
+	// ScreamingSnakeCase converts "UserID" into "USER_ID".
+	ScreamingSnakeCase
+	// TrainCase converts "UserID" into "User-Id". It is for HTTP header names.
+	TrainCase
+)
+
+func (n Naming) String() string {
+	switch n {
+	case LowerCase:
+		return "lowercase"
+	case SnakeCase:
+		return "snake_case"
+	case KebabCase:
+		return "kebab-case"
+	case CamelCase:
+		return "camelCase"
+	case PascalCase:
+		return "PascalCase"
+	case ScreamingSnakeCase:
+		return "SCREAMING_SNAKE_CASE"
+	case TrainCase:
+		return "Train-Case"
+	}
+	return "unknown"
+}
+
+// Convert converts field name in the naming strategy.
+func (n Naming) Convert(name string) string {
+	switch n {
+	case LowerCase:
+		return strings.ToLower(name)
+	case SnakeCase:
+		return joinWords(SplitWords(name), "_", strings.ToLower)
+	case KebabCase:
+		return joinWords(SplitWords(name), "-", strings.ToLower)
+	case ScreamingSnakeCase:
+		return joinWords(SplitWords(name), "_", strings.ToUpper)
+	case TrainCase:
+		return joinWords(SplitWords(name), "-", title)
+	case PascalCase:
+		return joinWords(SplitWords(name), "", title)
+	case CamelCase:
+		words := SplitWords(name)
+		if len(words) == 0 {
+			return ""
+		}
+		return strings.ToLower(words[0]) + joinWords(words[1:], "", title)
+	}
+	return name
+}
+
+// BasicParseTag is as same as runtimescan.BasicParseTag() except the naming strategy for fields without tag.
+func (n Naming) BasicParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (tag any, err error) {
+	if tagStr == "" {
+		tagStr = n.Convert(name)
+	}
+	return &BasicTag{
+		Name:     name,
+		TagKey:   tagKey,
+		Tag:      tagStr,
+		Path:     pathStr,
+		ElemType: elemType,
+	}, nil
+}
+
+func joinWords(words []string, sep string, conv func(string) string) string {
+	result := make([]string, len(words))
+	for i, w := range words {
+		result[i] = conv(w)
+	}
+	return strings.Join(result, sep)
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// mixedCaseWords are words that have lowercase letters between uppercase letters. SplitWords keeps them as one word.
+var mixedCaseWords = []string{"OAuth", "IPv4", "IPv6"}
+
+// SplitWords splits field name into words.
+//
+// Acronyms are kept as one word and digits belong to the preceding word:
+// "UserID" -> ["User", "ID"], "HTTPServer" -> ["HTTP", "Server"], "Base64Value" -> ["Base64", "Value"].
+// A lowercase 's' after an acronym is its plural form ("UserIDs" -> ["User", "IDs"]), and some mixed case
+// words are kept as is ("OAuth2Token" -> ["OAuth2", "Token"], "IPv4Addr" -> ["IPv4", "Addr"]).
+// Characters other than letters and digits like '_' and '-' are treated as separators.
+func SplitWords(name string) []string {
+	var result []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			result = append(result, string(word))
+			word = nil
+		}
+	}
+	isLower := func(i int) bool {
+		return i < len(runes) && unicode.IsLower(runes[i])
+	}
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case unicode.IsUpper(c):
+			if len(word) > 0 {
+				prev := runes[i-1]
+				// "IDs" is a plural of "ID" unless a lowercase letter follows like "HTTPService"
+				plural := isLower(i+1) && runes[i+1] == 's' && !isLower(i+2)
+				// "userID" -> "user", "ID" / "HTTPServer" -> "HTTP", "Server"
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && isLower(i+1) && !plural) {
+					flush()
+				}
+			}
+			if w := mixedCaseWordAt(runes, i); len(word) == 0 && w > 0 {
+				word = append(word, runes[i:i+w]...)
+				i += w - 1
+				continue
+			}
+			word = append(word, c)
+		case unicode.IsLower(c) || unicode.IsDigit(c):
+			word = append(word, c)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return result
+}
+
+// mixedCaseWordAt returns the length of the mixed case word at i. It returns 0 if there is no word
+// or the word continues with lowercase letters.
+func mixedCaseWordAt(runes []rune, i int) int {
+	for _, w := range mixedCaseWords {
+		wr := []rune(w)
+		end := i + len(wr)
+		if end <= len(runes) && string(runes[i:end]) == w && (end == len(runes) || !unicode.IsLower(runes[end])) {
+			return len(wr)
+		}
+	}
+	return 0
+}
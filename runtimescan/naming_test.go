@@ -0,0 +1,77 @@
+package runtimescan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "Name", want: []string{"Name"}},
+		{name: "UserName", want: []string{"User", "Name"}},
+		{name: "UserID", want: []string{"User", "ID"}},
+		{name: "userID", want: []string{"user", "ID"}},
+		{name: "HTTPServer", want: []string{"HTTP", "Server"}},
+		{name: "ID", want: []string{"ID"}},
+		{name: "Base64Value", want: []string{"Base64", "Value"}},
+		{name: "V2API", want: []string{"V2", "API"}},
+		{name: "user_id", want: []string{"user", "id"}},
+		{name: "X-Request-ID", want: []string{"X", "Request", "ID"}},
+		{name: "userIDs", want: []string{"user", "IDs"}},
+		{name: "MyURLs", want: []string{"My", "URLs"}},
+		{name: "URLsByID", want: []string{"URLs", "By", "ID"}},
+		{name: "HTTPStatus", want: []string{"HTTP", "Status"}},
+		{name: "OAuth2Token", want: []string{"OAuth2", "Token"}},
+		{name: "IPv4Addr", want: []string{"IPv4", "Addr"}},
+		{name: "ClientIPv6", want: []string{"Client", "IPv6"}},
+		{name: "OAuthor", want: []string{"O", "Author"}},
+		{name: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitWords(tt.name))
+		})
+	}
+}
+
+func TestNaming_Convert(t *testing.T) {
+	tests := []struct {
+		naming Naming
+		name   string
+		want   string
+	}{
+		{naming: LowerCase, name: "UserID", want: "userid"},
+		{naming: SnakeCase, name: "UserID", want: "user_id"},
+		{naming: SnakeCase, name: "HTTPServer2", want: "http_server2"},
+		{naming: SnakeCase, name: "userIDs", want: "user_ids"},
+		{naming: SnakeCase, name: "MyURLs", want: "my_urls"},
+		{naming: SnakeCase, name: "OAuth2Token", want: "oauth2_token"},
+		{naming: SnakeCase, name: "IPv4Addr", want: "ipv4_addr"},
+		{naming: KebabCase, name: "UserID", want: "user-id"},
+		{naming: CamelCase, name: "UserID", want: "userId"},
+		{naming: CamelCase, name: "HTTPServer", want: "httpServer"},
+		{naming: PascalCase, name: "user_id", want: "UserId"},
+		{naming: ScreamingSnakeCase, name: "DBHost", want: "DB_HOST"},
+		{naming: TrainCase, name: "ContentType", want: "Content-Type"},
+		{naming: TrainCase, name: "XRequestID", want: "X-Request-Id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.naming.String()+"/"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.naming.Convert(tt.name))
+		})
+	}
+}
+
+func TestNaming_BasicParseTag(t *testing.T) {
+	tag, err := SnakeCase.BasicParseTag("UserID", "map", "", "UserID", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "user_id", tag.(*BasicTag).Tag)
+
+	tag, err = SnakeCase.BasicParseTag("UserID", "map", "uid", "UserID", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "uid", tag.(*BasicTag).Tag)
+}
@@ -0,0 +1,86 @@
+package runtimescan
+
+import (
+	"strings"
+)
+
+// Option is an optional setting of Decode(), Encode() and other functions.
+type Option func(o *options)
+
+type options struct {
+	include       []string
+	exclude       []string
+	registry      *TypeRegistry
+	pathTag       string
+	concurrency   int
+	tracer        Tracer
+	unexported    bool
+	unsafeAssign  bool
+	jsonEmbedding bool
+	omitEmpty     bool
+	// visitMissing is set by EncodeAll() with HeaderEncoder. Fields without values are visited as nil.
+	visitMissing bool
+}
+
+func newOptions(opts []Option) *options {
+	result := &options{}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// Include restricts traversal to fields that match one of the patterns.
+//
+// See FieldMask for pattern syntax.
+func Include(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// Exclude skips fields that match one of the patterns. Exclude has priority over Include.
+//
+// See FieldMask for pattern syntax.
+func Exclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// programKey identifies the options that change the compiled program.
+type programKey struct {
+	include       string
+	exclude       string
+	jsonEmbedding bool
+}
+
+// program returns compiled parser that options are applied.
+//
+// The result is cached in p, so patterns are parsed and fields are pruned only once per combination of options.
+// The cache keeps only recently used combinations (maxPrograms) because masks may come from each request.
+func (o *options) program(p *parser) (*parser, error) {
+	if !o.jsonEmbedding && len(o.include) == 0 && len(o.exclude) == 0 {
+		return p, nil
+	}
+	key := programKey{
+		include:       strings.Join(o.include, "\x00"),
+		exclude:       strings.Join(o.exclude, "\x00"),
+		jsonEmbedding: o.jsonEmbedding,
+	}
+	if cached, ok := p.programs.get(key); ok {
+		return cached, nil
+	}
+	result := p
+	if o.jsonEmbedding {
+		result = result.resolveEmbedding()
+	}
+	if len(o.include) > 0 || len(o.exclude) > 0 {
+		mask, err := NewFieldMask(o.include, o.exclude)
+		if err != nil {
+			return nil, err
+		}
+		result = result.prune(mask)
+	}
+	return p.programs.put(key, result), nil
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"sync"
 	"unicode"
 )
 
@@ -17,27 +16,93 @@ const (
 )
 
 type field struct {
+	accessor
+	path  string
 	tag   any
 	eKind reflect.Kind
 	eType reflect.Type
 	isPtr bool
+	// decodable and encodable are true when eType implements TagDecodable and TagEncodable.
+	decodable bool
+	encodable bool
+	// kindTag is a parsed DiscriminatorTag of polymorphic interface field.
+	kindTag any
+	hasKind bool
+	// typed is a method of TypedDecoder that is used for this field.
+	typed typedKind
+	// name is the first part of the tag or the field name. tagged is true if the tag is not empty.
+	// They are used by JSONEmbedding() option.
+	name   string
+	tagged bool
+	// omitEmpty is true if the tag has "omitempty" option. It is used by OmitEmpty() option.
+	omitEmpty bool
 }
 
+// fieldType returns the type of the field itself.
+func (f *field) fieldType() reflect.Type {
+	if f.isPtr {
+		return reflect.PointerTo(f.eType)
+	}
+	return f.eType
+}
+
+var stringType = reflect.TypeOf("")
+
+// discriminator returns a string field that has the DiscriminatorTag of the polymorphic field as its tag.
+func (f *field) discriminator() *field {
+	return &field{
+		path:      f.path,
+		tag:       f.kindTag,
+		eKind:     reflect.String,
+		eType:     stringType,
+		omitEmpty: f.omitEmpty,
+	}
+}
+
+// child is an information of child struct. visitChildOp and leaveChildOp share it.
+type child struct {
+	accessor
+	path     string
+	embedded bool
+	hooks    hookFlags
+	name     string
+	tagged   bool
+}
+
+// skippedField is a field that is dropped when compiling. It is reported to Tracer.
+type skippedField struct {
+	path   string
+	tag    any
+	reason string
+}
+
+// parser is a compiled program of the struct.
+//
+// fields, fieldOps and children are parallel slices. Decode and Encode access fields directly from the root
+// struct by the full index sequences of field and child (accessor.index) instead of walking nested structs.
 type parser struct {
 	errors           []error
+	tags             []string
+	tagKey           string
 	fields           []*field
-	fieldIndexes     []int
 	fieldOps         []visitOpType
+	children         []*child
+	skipped          []*skippedField
 	panicWhenParsing bool
+	describe         bool
+	unexported       bool
+	descriptions     []*FieldDescription
+	// programs caches programs that options are applied. See options.program().
+	programs programCache
 }
 
-func newParser(vi Parser, tags []string, s any) (*parser, error) {
+func newParser(vi Parser, tags []string, s any, unexported bool) (*parser, error) {
 	err := shouldPointerOfStruct(s)
 	if err != nil {
 		return nil, err
 	}
 
-	visitor := &parser{}
+	visitor := &parser{unexported: unexported}
 	visitor.parse(vi, tags, reflect.ValueOf(s).Type().Elem())
 	if len(visitor.errors) == 0 {
 		return visitor, nil
@@ -60,15 +125,28 @@ func shouldPointerOfStruct(s any) error {
 func (d *parser) parse(vi Parser, tags []string, t reflect.Type) error {
 	d.errors = nil
 	d.fields = nil
-	d.fieldIndexes = nil
 	d.fieldOps = nil
-	d.parseTags(vi, tags, t, nil)
+	d.children = nil
+	d.skipped = nil
+	d.descriptions = nil
+	d.tags = tags
+	if len(tags) > 0 {
+		d.tagKey = tags[0]
+	}
+	d.parseTags(vi, tags, t, nil, accessor{}, &d.descriptions)
 	return nil
 }
 
 func isPublic(f reflect.StructField) bool {
 	if f.Anonymous {
-		return true
+		// exported fields of embedded unexported struct are still accessible
+		t := f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return true
+		}
 	}
 	var first rune
 	for _, r := range f.Name {
@@ -78,26 +156,21 @@ func isPublic(f reflect.StructField) bool {
 	return unicode.IsUpper(first)
 }
 
-func (d *parser) parseTags(vi Parser, tags []string, t reflect.Type, path []string) {
+func (d *parser) parseTags(vi Parser, tags []string, t reflect.Type, path []string, parent accessor, descriptions *[]*FieldDescription) {
 	for i := 0; i < t.NumField(); i++ {
 		index := i
 		f := t.Field(i)
-		if !isPublic(f) {
+		isUnexported := parent.unexported || !isPublic(f)
+		if isUnexported && !d.unexported {
 			continue
 		}
-		var name string
-		var hasChild bool
-		if f.Anonymous {
-			name = "(embed)"
-			hasChild = true
-		} else {
-			name = f.Name
-			if f.Type.Kind() == reflect.Struct {
-				hasChild = true
-			}
-		}
 
-		currentPath := append(path, name)
+		currentPath := append(path[:len(path):len(path)], f.Name)
+		current := accessor{
+			index:      append(parent.index[:len(parent.index):len(parent.index)], index),
+			unexported: isUnexported,
+			viaPtr:     parent.viaPtr,
+		}
 		pathStr := strings.Join(currentPath, ".")
 		isPtr := t.Field(i).Type.Kind() == reflect.Ptr
 		var eKind reflect.Kind
@@ -109,6 +182,8 @@ func (d *parser) parseTags(vi Parser, tags []string, t reflect.Type, path []stri
 			eKind = t.Field(i).Type.Kind()
 			eType = t.Field(i).Type
 		}
+		// embedded pointer of struct is traversed as same as embedded struct
+		hasChild := (f.Type.Kind() == reflect.Struct || (f.Anonymous && eKind == reflect.Struct)) && !isTagDecodable(eType) && !isTagEncodable(eType)
 		var tag, tagKey string
 		for _, t := range tags {
 			tag = f.Tag.Get(t)
@@ -117,72 +192,162 @@ func (d *parser) parseTags(vi Parser, tags []string, t reflect.Type, path []stri
 				break
 			}
 		}
-		t, err := vi.ParseTag(f.Name, tagKey, tag, pathStr, eType)
+		name := f.Name
+		if n := strings.SplitN(tag, ",", 2)[0]; n != "" {
+			name = n
+		}
+		var t any
+		var err error
+		var method string
+		if up, ok := vi.(UnexportedParser); ok && isUnexported {
+			t, err = up.ParseUnexportedTag(f.Name, tagKey, tag, pathStr, eType)
+			method = "ParseUnexportedTag"
+		} else if mp, ok := vi.(MergedTagParser); ok {
+			t, err = mp.ParseTags(f.Name, lookupTags(f, tags), pathStr, eType)
+			method = "ParseTags"
+		} else {
+			t, err = vi.ParseTag(f.Name, tagKey, tag, pathStr, eType)
+			method = "ParseTag"
+		}
 		var skipTraverse bool
 		var skipAdd bool
+		var desc *FieldDescription
+		if d.describe {
+			desc = &FieldDescription{
+				Path:     pathStr,
+				Name:     f.Name,
+				Type:     f.Type.String(),
+				TagKey:   tagKey,
+				RawTag:   tag,
+				Tag:      t,
+				Embedded: f.Anonymous,
+			}
+			*descriptions = append(*descriptions, desc)
+		}
 		if err == Skip {
 			skipAdd = true
 		} else if err == SkipTraverse {
 			skipTraverse = true
 		} else if err != nil {
 			d.errors = append(d.errors, err)
+			if desc != nil {
+				desc.Error = err.Error()
+			}
 			continue
 		}
+		if desc != nil {
+			desc.Skipped = skipAdd
+			desc.SkipTraverse = skipTraverse
+			if hasChild && !skipTraverse {
+				desc.Op = ChildOp
+			} else {
+				desc.Op = FieldOp
+			}
+		}
+		var children *[]*FieldDescription
+		if desc != nil {
+			children = &desc.Children
+		}
 		if hasChild && !skipTraverse {
-			d.fieldIndexes = append(d.fieldIndexes, index)
+			c := &child{
+				accessor: current,
+				path:     pathStr,
+				embedded: f.Anonymous,
+				hooks:    detectHooks(eType),
+				name:     name,
+				tagged:   tag != "",
+			}
 			d.fieldOps = append(d.fieldOps, visitChildOp)
+			d.children = append(d.children, c)
 			if !skipAdd {
 				d.fields = append(d.fields, &field{
-					tag:   t,
-					eType: eType,
-					eKind: eKind,
-					isPtr: isPtr,
+					accessor: current,
+					path:     pathStr,
+					tag:      t,
+					eType:    eType,
+					eKind:    eKind,
+					isPtr:    isPtr,
+					name:     name,
+					tagged:   tag != "",
 				})
 			} else {
 				d.fields = append(d.fields, nil)
 			}
-			d.parseTags(vi, tags, f.Type, path)
-			d.fieldIndexes = append(d.fieldIndexes, -1)
+			inner := current
+			inner.viaPtr = current.viaPtr || isPtr
+			d.parseTags(vi, tags, eType, currentPath, inner, children)
 			d.fieldOps = append(d.fieldOps, leaveChildOp)
 			d.fields = append(d.fields, nil)
+			d.children = append(d.children, c)
 		} else if !skipAdd {
-			d.fieldIndexes = append(d.fieldIndexes, index)
+			fi := &field{
+				accessor:  current,
+				path:      pathStr,
+				tag:       t,
+				eType:     eType,
+				eKind:     eKind,
+				isPtr:     isPtr,
+				decodable: isTagDecodable(eType),
+				encodable: isTagEncodable(eType),
+				name:      name,
+				tagged:    tag != "",
+				omitEmpty: hasOmitEmpty(tag),
+			}
+			if kind := f.Tag.Get(DiscriminatorTag); kind != "" && eKind == reflect.Interface {
+				kt, err := vi.ParseTag(f.Name, tagKey, kind, pathStr, reflect.TypeOf(kind))
+				if err != nil {
+					d.errors = append(d.errors, err)
+					continue
+				}
+				fi.kindTag = kt
+				fi.hasKind = true
+			}
+			if !fi.decodable && !fi.hasKind {
+				fi.typed = detectTypedKind(eType)
+			}
 			d.fieldOps = append(d.fieldOps, visitFieldOp)
-			d.fields = append(d.fields, &field{
-				tag:   t,
-				eType: eType,
-				eKind: eKind,
-				isPtr: isPtr,
-			})
+			d.children = append(d.children, nil)
+			d.fields = append(d.fields, fi)
+		} else {
+			d.skipped = append(d.skipped, &skippedField{path: pathStr, tag: t, reason: method + " returned Skip"})
 		}
 	}
 }
 
 type parserCacheKey struct {
-	Type   reflect.Type
-	Parser reflect.Type
-	Tag    string
+	Type       reflect.Type
+	Parser     reflect.Type
+	Tag        string
+	Unexported bool
 }
 
-var parsers = sync.Map{}
-
-func getParser(dest any, tags []string, p Parser) (*parser, error) {
+func getParser(dest any, tags []string, p Parser, unexported bool) (*parser, error) {
 	err := shouldPointerOfStruct(dest)
 	if err != nil {
 		return nil, err
 	}
 	key := parserCacheKey{
-		Type:   reflect.ValueOf(dest).Type(),
-		Parser: reflect.ValueOf(p).Elem().Type(),
-		Tag:    strings.Join(tags, ":"),
+		Type:       reflect.ValueOf(dest).Type(),
+		Parser:     reflect.ValueOf(p).Elem().Type(),
+		Tag:        strings.Join(tags, ":"),
+		Unexported: unexported,
 	}
-	v, ok := parsers.Load(key)
+	v, ok := parsers.get(key)
 	if !ok {
-		v, err = newParser(p, tags, dest)
+		v, err = newParser(p, tags, dest, unexported)
 		if err != nil {
 			return nil, err
 		}
-		parsers.Store(key, v)
+		parsers.put(key, v)
+	}
+	return v, nil
+}
+
+// getProgram returns compiled parser that options are applied. write should be true if the caller assigns fields.
+func getProgram(dest any, tags []string, p Parser, o *options, write bool) (*parser, error) {
+	v, err := getParser(dest, tags, p, o.traverseUnexported(write))
+	if err != nil {
+		return nil, err
 	}
-	return v.(*parser), nil
+	return o.program(v)
 }
@@ -174,7 +174,7 @@ func Test_visitor_parse(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantFieldIndexes, v.fieldIndexes)
+				assert.Equal(t, tt.wantFieldIndexes, opIndexes(v))
 				assert.Equal(t, tt.wantFieldOps, v.fieldOps)
 				validFieldCount := 0
 				for _, f := range v.fields {
@@ -188,6 +188,32 @@ func Test_visitor_parse(t *testing.T) {
 	}
 }
 
+func Test_visitor_parse_index(t *testing.T) {
+	type E struct {
+		I int `rest:"i"`
+	}
+	type C struct {
+		E
+		J int `rest:"j"`
+	}
+	type S struct {
+		S int `rest:"s"`
+		C C
+	}
+	v := &parser{}
+	err := v.parse(&dummyVisitor{}, []string{"rest"}, reflect.TypeOf(S{}))
+	assert.NoError(t, err)
+	var indexes [][]int
+	for _, f := range v.fields {
+		if f != nil {
+			indexes = append(indexes, f.index)
+		}
+	}
+	assert.Equal(t, [][]int{{0}, {1, 0, 0}, {1, 1}}, indexes)
+	assert.Equal(t, []int{1}, v.children[1].index)
+	assert.Equal(t, []int{1, 0}, v.children[2].index)
+}
+
 type TestStruct struct {
 	FileHeader *multipart.FileHeader `rest:"file"`
 	FileFile   multipart.File        `rest:"file"`
@@ -230,3 +256,54 @@ func (dv dummyStructVisitor) ParseTag(name, tag, pathStr string) (any, error) {
 
 	assert.Equal(t, "GET", result.Method)
 }*/
+
+type pathVisitor struct {
+	paths []string
+}
+
+func (p *pathVisitor) ParseTag(name, tagKey, tag, pathStr string, eType reflect.Type) (any, error) {
+	p.paths = append(p.paths, pathStr)
+	return nil, nil
+}
+
+func Test_visitor_parse_path(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Child struct {
+		Value int
+	}
+	type S struct {
+		Base
+		Child Child
+		Name  string
+	}
+	p := &pathVisitor{}
+	v := &parser{}
+	err := v.parse(p, []string{"rest"}, reflect.TypeOf(S{}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Base", "Base.ID", "Child", "Child.Value", "Name"}, p.paths)
+	var fieldPaths []string
+	for _, f := range v.fields {
+		if f != nil {
+			fieldPaths = append(fieldPaths, f.path)
+		}
+	}
+	assert.Equal(t, []string{"Base", "Base.ID", "Child", "Child.Value", "Name"}, fieldPaths)
+}
+
+// opIndexes returns the indexes of fields and children in their parent structs. leaveChildOp is -1.
+func opIndexes(v *parser) []int {
+	var result []int
+	for i, op := range v.fieldOps {
+		switch op {
+		case visitFieldOp:
+			result = append(result, v.fields[i].index[len(v.fields[i].index)-1])
+		case visitChildOp:
+			result = append(result, v.children[i].index[len(v.children[i].index)-1])
+		case leaveChildOp:
+			result = append(result, -1)
+		}
+	}
+	return result
+}
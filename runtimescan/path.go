@@ -0,0 +1,193 @@
+package runtimescan
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// WithPathTag makes Get() and Set() resolve field path segments by tag names instead of Go field names.
+//
+// The tag name is the first comma separated element of the tag like encoding/json.
+// Fields without the tag are resolved by Go field names and fields of untagged embedded structs are promoted.
+func WithPathTag(tagKey string) Option {
+	return func(o *options) {
+		o.pathTag = tagKey
+	}
+}
+
+// Get returns the value of the field that the path points.
+//
+// obj should be a struct or a pointer of struct. The path syntax is as same as the field path
+// runtimescan passes to ParseTag(): "Address.City", "Base.ID" for a field of embedded struct
+// (promoted "ID" is also accepted) and "Items[2].Price" for an element of slice or array.
+func Get(obj any, path string, opts ...Option) (any, error) {
+	o := newOptions(opts)
+	segments, err := parseFieldPath(path, false)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("obj should be struct or pointer of struct, but nil: %w", ErrInvalidPath)
+	}
+	for _, seg := range segments {
+		v, err = resolveSegment(v, seg, path, o.pathTag, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !v.CanInterface() {
+		return nil, fmt.Errorf("field path '%s' points unexported field: %w", path, ErrInvalidPath)
+	}
+	return v.Interface(), nil
+}
+
+// Set assigns the value to the field that the path points by using FuzzyAssign().
+//
+// obj should be a pointer of struct. Nil pointers on the path are allocated.
+// See Get() for the path syntax.
+func Set(obj any, path string, value any, opts ...Option) error {
+	if !IsPointerOfStruct(obj) {
+		return fmt.Errorf("obj should be pointer of struct, but %T: %w", obj, ErrAssignError)
+	}
+	o := newOptions(opts)
+	segments, err := parseFieldPath(path, false)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(obj)
+	for _, seg := range segments {
+		v, err = resolveSegment(v, seg, path, o.pathTag, true)
+		if err != nil {
+			return err
+		}
+	}
+	if !v.CanSet() {
+		return fmt.Errorf("field path '%s' points unexported field: %w", path, ErrAssignError)
+	}
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	return FuzzyAssign(v, value)
+}
+
+// resolveSegment returns the field (and the element of indexes) of v.
+// If alloc is true, nil pointers are allocated.
+func resolveSegment(v reflect.Value, seg maskSegment, path, tagKey string, alloc bool) (reflect.Value, error) {
+	v, err := deref(v, path, alloc)
+	if err != nil {
+		return v, err
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("field path '%s' is invalid: '%s' is not a field of struct (%s): %w", path, seg.name, v.Type(), ErrInvalidPath)
+	}
+	index, ok := findFieldIndex(v.Type(), seg.name, tagKey)
+	if !ok {
+		return v, fmt.Errorf("field path '%s' is invalid: field '%s' is not found in %s: %w", path, seg.name, v.Type(), ErrInvalidPath)
+	}
+	v, err = fieldByIndex(v, index, path, alloc)
+	if err != nil {
+		return v, err
+	}
+	for _, is := range seg.indexes {
+		v, err = deref(v, path, alloc)
+		if err != nil {
+			return v, err
+		}
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return v, fmt.Errorf("field path '%s' is invalid: '%s' is not slice or array: %w", path, seg.name, ErrInvalidPath)
+		}
+		i, err := strconv.Atoi(is)
+		if err != nil {
+			return v, fmt.Errorf("field path '%s' is invalid: index '%s' is not a number: %w", path, is, ErrInvalidPath)
+		}
+		if i < 0 || i >= v.Len() {
+			return v, fmt.Errorf("field path '%s' is invalid: index %d is out of range (length %d): %w", path, i, v.Len(), ErrInvalidPath)
+		}
+		v = v.Index(i)
+	}
+	return v, nil
+}
+
+func deref(v reflect.Value, path string, alloc bool) (reflect.Value, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if !alloc || v.Kind() == reflect.Interface {
+				return v, fmt.Errorf("field path '%s' has nil value on the way", path)
+			}
+			if !v.CanSet() {
+				return v, fmt.Errorf("field path '%s' has nil value that can't be allocated on the way: %w", path, ErrAssignError)
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v, nil
+}
+
+// fieldByIndex is as same as reflect.Value.FieldByIndex() but it allocates nil embedded pointers if alloc is true.
+func fieldByIndex(v reflect.Value, index []int, path string, alloc bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 {
+			var err error
+			v, err = deref(v, path, alloc)
+			if err != nil {
+				return v, err
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// findFieldIndex finds the public field by name. If tagKey is not empty, tag names are used.
+func findFieldIndex(t reflect.Type, name, tagKey string) ([]int, bool) {
+	if tagKey == "" {
+		f, ok := t.FieldByName(name)
+		if !ok || (!f.IsExported() && !f.Anonymous) {
+			return nil, false
+		}
+		return f.Index, true
+	}
+	var embedded [][]int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isPublic(f) {
+			continue
+		}
+		tag := f.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		if tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && ft.Kind() == reflect.Struct {
+				embedded = append(embedded, []int{i})
+				continue
+			}
+			if f.Name == name {
+				return []int{i}, true
+			}
+			continue
+		}
+		if opts, err := ParseOptions(tag); err == nil && opts.Name == name {
+			return []int{i}, true
+		}
+	}
+	// promoted fields of embedded structs
+	for _, e := range embedded {
+		ft := t.Field(e[0]).Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if index, ok := findFieldIndex(ft, name, tagKey); ok {
+			return append(e, index...), true
+		}
+	}
+	return nil, false
+}
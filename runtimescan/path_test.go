@@ -0,0 +1,118 @@
+package runtimescan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pathBase struct {
+	ID int `json:"id"`
+}
+
+type pathItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price,omitempty"`
+}
+
+type pathAddress struct {
+	City string `json:"city"`
+}
+
+type pathOrder struct {
+	pathBase
+	Customer string       `json:"customer"`
+	Address  *pathAddress `json:"address"`
+	Items    []pathItem   `json:"items"`
+	Matrix   [2][2]int
+	Secret   string `json:"-"`
+	private  string
+}
+
+func TestGet(t *testing.T) {
+	o := pathOrder{
+		pathBase: pathBase{ID: 10},
+		Customer: "customer",
+		Address:  &pathAddress{City: "Tokyo"},
+		Items: []pathItem{
+			{Name: "apple", Price: 100},
+			{Name: "orange", Price: 200},
+		},
+		Matrix: [2][2]int{{1, 2}, {3, 4}},
+	}
+	tests := []struct {
+		name    string
+		path    string
+		opts    []Option
+		want    any
+		wantErr bool
+	}{
+		{name: "field", path: "Customer", want: "customer"},
+		{name: "nested pointer", path: "Address.City", want: "Tokyo"},
+		{name: "embedded segment", path: "pathBase.ID", want: 10},
+		{name: "promoted", path: "ID", want: 10},
+		{name: "slice index", path: "Items[1].Price", want: 200.0},
+		{name: "slice element", path: "Items[0]", want: pathItem{Name: "apple", Price: 100}},
+		{name: "array of array", path: "Matrix[1][0]", want: 3},
+		{name: "tag name", path: "items[1].name", opts: []Option{WithPathTag("json")}, want: "orange"},
+		{name: "tag name with options", path: "items[0].price", opts: []Option{WithPathTag("json")}, want: 100.0},
+		{name: "promoted tag name", path: "id", opts: []Option{WithPathTag("json")}, want: 10},
+		{name: "untagged field with tag", path: "Matrix[0][1]", opts: []Option{WithPathTag("json")}, want: 2},
+		{name: "ignored tag", path: "Secret", opts: []Option{WithPathTag("json")}, wantErr: true},
+		{name: "not found", path: "Unknown", wantErr: true},
+		{name: "private", path: "private", wantErr: true},
+		{name: "unexported embedded struct", path: "pathBase", wantErr: true},
+		{name: "out of range", path: "Items[2].Name", wantErr: true},
+		{name: "not slice", path: "Customer[0]", wantErr: true},
+		{name: "syntax error", path: "Items[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(&o, tt.path, tt.opts...)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidPath), err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	t.Run("nil pointer", func(t *testing.T) {
+		_, err := Get(pathOrder{}, "Address.City")
+		assert.Error(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := Get(nil, "Customer")
+		assert.True(t, errors.Is(err, ErrInvalidPath), err)
+	})
+}
+
+func TestSet(t *testing.T) {
+	o := pathOrder{
+		Items: []pathItem{{Name: "apple"}},
+	}
+	assert.NoError(t, Set(&o, "Customer", "customer"))
+	assert.NoError(t, Set(&o, "Address.City", "Osaka"))
+	assert.NoError(t, Set(&o, "ID", "12"))
+	assert.NoError(t, Set(&o, "items[0].price", "99.5", WithPathTag("json")))
+	assert.NoError(t, Set(&o, "Matrix[1][1]", 4))
+
+	assert.Equal(t, "customer", o.Customer)
+	assert.Equal(t, &pathAddress{City: "Osaka"}, o.Address)
+	assert.Equal(t, 12, o.ID)
+	assert.Equal(t, 99.5, o.Items[0].Price)
+	assert.Equal(t, 4, o.Matrix[1][1])
+
+	assert.NoError(t, Set(&o, "Address", nil))
+	assert.Nil(t, o.Address)
+
+	assert.True(t, errors.Is(Set(&o, "Items[3].Name", "x"), ErrInvalidPath))
+	assert.True(t, errors.Is(Set(o, "Customer", "x"), ErrAssignError))
+	assert.True(t, errors.Is(Set((*pathOrder)(nil), "Customer", "x"), ErrAssignError))
+	assert.True(t, errors.Is(Set(&o, "pathBase", nil), ErrAssignError))
+	assert.True(t, errors.Is(Set(&o, "pathBase", pathBase{ID: 1}), ErrAssignError))
+	assert.Equal(t, 12, o.ID)
+}
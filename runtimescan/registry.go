@@ -0,0 +1,106 @@
+package runtimescan
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// DiscriminatorTag is a tag key to declare the discriminator of polymorphic interface field.
+//
+//	type Event struct {
+//		Payload Payload `map:"payload" kind:"type"`
+//	}
+//
+// The tag value is parsed by ParseTag() as same as the field's tag of a string field
+// (in this case, as if `map:"type"`), and Decode() extracts the discriminator by ExtractValue().
+// Then it instantiates the concrete type registered in TypeRegistry under the name and decodes
+// its fields by the same decoder. Encode() visits the discriminator and the fields of the concrete type.
+const DiscriminatorTag = "kind"
+
+// TypeRegistry keeps concrete types of polymorphic interface fields with their names.
+//
+// Pass it to Decode() and Encode() by WithTypeRegistry() option.
+type TypeRegistry struct {
+	lock   sync.RWMutex
+	byName map[string]registeredType
+	byType map[reflect.Type]string
+}
+
+type registeredType struct {
+	t     reflect.Type
+	isPtr bool
+}
+
+// NewTypeRegistry creates TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		byName: make(map[string]registeredType),
+		byType: make(map[reflect.Type]string),
+	}
+}
+
+// Register registers the type of sample under the name.
+//
+// sample should be struct or pointer of struct. If it is a pointer, Decode() stores
+// a pointer into the interface field.
+func (r *TypeRegistry) Register(name string, sample any) error {
+	t := reflect.TypeOf(sample)
+	if t == nil {
+		return fmt.Errorf("sample of type '%s' is nil", name)
+	}
+	rt := registeredType{t: t}
+	if t.Kind() == reflect.Pointer {
+		rt.t = t.Elem()
+		rt.isPtr = true
+	}
+	if rt.t.Kind() != reflect.Struct {
+		return fmt.Errorf("type '%s' should be struct or pointer of struct, but %s", name, t)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("type '%s' is already registered", name)
+	}
+	r.byName[name] = rt
+	r.byType[t] = name
+	return nil
+}
+
+// MustRegister is as same as Register() but panics when error occurs.
+func (r *TypeRegistry) MustRegister(name string, sample any) {
+	if err := r.Register(name, sample); err != nil {
+		panic(err)
+	}
+}
+
+// Name returns the registered name of the value's type.
+func (r *TypeRegistry) Name(value any) (string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	name, ok := r.byType[reflect.TypeOf(value)]
+	return name, ok
+}
+
+func (r *TypeRegistry) lookup(name, pathStr string) (registeredType, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	rt, ok := r.byName[name]
+	if ok {
+		return rt, nil
+	}
+	names := make([]string, 0, len(r.byName))
+	for n := range r.byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return registeredType{}, fmt.Errorf("type '%s' of field '%s' is not registered. did you mean '%s'?", name, pathStr, nearest(name, names))
+}
+
+// WithTypeRegistry sets TypeRegistry that is used for polymorphic interface fields.
+func WithTypeRegistry(r *TypeRegistry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
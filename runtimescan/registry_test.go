@@ -0,0 +1,172 @@
+package runtimescan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type eventPayload interface {
+	eventName() string
+}
+
+type userCreated struct {
+	UserID string `map:"user_id"`
+}
+
+func (u userCreated) eventName() string {
+	return "created"
+}
+
+type userDeleted struct {
+	UserID string `map:"user_id"`
+	Reason string `map:"reason"`
+}
+
+func (u *userDeleted) eventName() string {
+	return "deleted"
+}
+
+type event struct {
+	ID      string       `map:"id"`
+	Payload eventPayload `map:"payload" kind:"type"`
+}
+
+func newEventRegistry() *TypeRegistry {
+	r := NewTypeRegistry()
+	r.MustRegister("user.created", userCreated{})
+	r.MustRegister("user.deleted", &userDeleted{})
+	return r
+}
+
+func TestTypeRegistry_Register(t *testing.T) {
+	r := NewTypeRegistry()
+	assert.NoError(t, r.Register("created", userCreated{}))
+	assert.Error(t, r.Register("created", &userDeleted{}))
+	assert.Error(t, r.Register("int", 1))
+	assert.Error(t, r.Register("nil", nil))
+	name, ok := r.Name(userCreated{})
+	assert.True(t, ok)
+	assert.Equal(t, "created", name)
+	_, ok = r.Name(&userCreated{})
+	assert.False(t, ok)
+}
+
+func TestPolymorphicDecode(t *testing.T) {
+	t.Run("value type", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"id":      "1",
+				"type":    "user.created",
+				"user_id": "u1",
+			},
+		}
+		e := event{}
+		result, err := DecodeWithResult(&e, []string{"map"}, &d, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, userCreated{UserID: "u1"}, e.Payload)
+		assert.Equal(t, []string{"ID", "Payload.UserID", "Payload"}, result.Set)
+	})
+
+	t.Run("pointer type", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"type":    "user.deleted",
+				"user_id": "u2",
+				"reason":  "spam",
+			},
+		}
+		e := event{}
+		err := Decode(&e, []string{"map"}, &d, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, &userDeleted{UserID: "u2", Reason: "spam"}, e.Payload)
+	})
+
+	t.Run("discriminator is missing", func(t *testing.T) {
+		e := event{}
+		result, err := DecodeWithResult(&e, []string{"map"}, &mapDecoder{}, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Nil(t, e.Payload)
+		assert.Equal(t, []string{"ID", "Payload"}, result.Skipped)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"type": "user.create",
+			},
+		}
+		e := event{}
+		err := Decode(&e, []string{"map"}, &d, WithTypeRegistry(newEventRegistry()))
+		assert.EqualError(t, err, "1 errors: \n* type 'user.create' of field 'Payload' is not registered. did you mean 'user.created'?")
+	})
+
+	t.Run("no registry", func(t *testing.T) {
+		d := mapDecoder{
+			values: map[string]any{
+				"type": "user.created",
+			},
+		}
+		e := event{}
+		err := Decode(&e, []string{"map"}, &d)
+		assert.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "TypeRegistry is not passed"))
+	})
+}
+
+func TestPolymorphicEncode(t *testing.T) {
+	t.Run("value type", func(t *testing.T) {
+		m := mapEncoder{
+			result: make(map[string]any),
+		}
+		e := event{ID: "1", Payload: userCreated{UserID: "u1"}}
+		err := Encode(&e, []string{"map"}, &m, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "1", "type": "user.created", "user_id": "u1"}, m.result)
+	})
+
+	t.Run("pointer type", func(t *testing.T) {
+		m := mapEncoder{
+			result: make(map[string]any),
+		}
+		e := event{ID: "2", Payload: &userDeleted{UserID: "u2", Reason: "spam"}}
+		err := Encode(&e, []string{"map"}, &m, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "2", "type": "user.deleted", "user_id": "u2", "reason": "spam"}, m.result)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		m := mapEncoder{
+			result: make(map[string]any),
+		}
+		e := event{ID: "3"}
+		err := Encode(&e, []string{"map"}, &m, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "3", "type": nil}, m.result)
+	})
+	t.Run("field visitor", func(t *testing.T) {
+		v := &fieldValueEncoder{values: map[string]FieldValue{}}
+		e := event{ID: "4", Payload: userCreated{UserID: "u4"}}
+		err := Encode(&e, []string{"map"}, v, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, FieldValue{Value: "user.created", Original: "user.created"}, v.values["Payload"])
+		assert.Equal(t, "u4", v.values["UserID"].Value)
+
+		v = &fieldValueEncoder{values: map[string]FieldValue{}}
+		err = Encode(&event{ID: "5"}, []string{"map"}, v, WithTypeRegistry(newEventRegistry()))
+		assert.NoError(t, err)
+		assert.Equal(t, FieldValue{Value: nil, Original: (*string)(nil), IsPtr: true, IsNil: true, IsZero: true}, v.values["Payload"])
+	})
+
+	t.Run("omitempty", func(t *testing.T) {
+		type optionalEvent struct {
+			ID      string       `map:"id"`
+			Payload eventPayload `map:"payload,omitempty" kind:"type"`
+		}
+		m := &omitEncoder{result: map[string]any{}}
+		err := Encode(&optionalEvent{ID: "6"}, []string{"map"}, m, WithTypeRegistry(newEventRegistry()), OmitEmpty())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "6"}, m.result)
+	})
+}
@@ -0,0 +1,80 @@
+package runtimescan
+
+// DefaultValue is a value that Decoder's ExtractValue() returns when the source doesn't have
+// the value and the decoder uses a default value instead.
+//
+// Decode() assigns Value to the field and DecodeWithResult() reports the field as defaulted.
+type DefaultValue struct {
+	Value any
+}
+
+// Default wraps the value as DefaultValue. It is for ExtractValue() implementation.
+func Default(value any) any {
+	return DefaultValue{Value: value}
+}
+
+// DecodeResult is a summary of DecodeWithResult(). Each field has field paths in traversal order.
+type DecodeResult struct {
+	// Set is a list of fields that got values from the source.
+	Set []string
+	// Skipped is a list of fields that ExtractValue() returned Skip.
+	Skipped []string
+	// Defaulted is a list of fields that got DefaultValue from ExtractValue().
+	Defaulted []string
+	// Failed is a list of fields that ExtractValue() or assignment returned error.
+	Failed []string
+}
+
+// IsSet returns true if the field got the value from the source.
+func (r DecodeResult) IsSet(path string) bool {
+	return contains(r.Set, path)
+}
+
+// IsSkipped returns true if the field was skipped.
+func (r DecodeResult) IsSkipped(path string) bool {
+	return contains(r.Skipped, path)
+}
+
+// IsDefaulted returns true if the field got default value.
+func (r DecodeResult) IsDefaulted(path string) bool {
+	return contains(r.Defaulted, path)
+}
+
+// IsFailed returns true if decoding the field failed.
+func (r DecodeResult) IsFailed(path string) bool {
+	return contains(r.Failed, path)
+}
+
+type resultKind int
+
+const (
+	resultSet resultKind = iota + 1
+	resultSkipped
+	resultDefaulted
+	resultFailed
+)
+
+func (r *DecodeResult) add(kind resultKind, path string) {
+	if r == nil {
+		return
+	}
+	switch kind {
+	case resultSet:
+		r.Set = append(r.Set, path)
+	case resultSkipped:
+		r.Skipped = append(r.Skipped, path)
+	case resultDefaulted:
+		r.Defaulted = append(r.Defaulted, path)
+	case resultFailed:
+		r.Failed = append(r.Failed, path)
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
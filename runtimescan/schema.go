@@ -0,0 +1,108 @@
+package runtimescan
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+)
+
+// Schema describes a struct type that is known only at runtime.
+//
+// Type() builds the struct type by reflect.StructOf(). reflect.StructOf() returns the same
+// type for the same fields, so the parser cache doesn't grow when the same schema is used repeatedly.
+type Schema struct {
+	Fields []SchemaField
+}
+
+// SchemaField is a field definition of Schema.
+type SchemaField struct {
+	// Name is a Go field name. It should be an exported identifier. It is also used as a key of map.
+	Name string
+	// Type is a field type. It is ignored if Schema is set.
+	Type reflect.Type
+	// Tag is a struct tag like `map:"id"`.
+	Tag reflect.StructTag
+	// Schema is set when the field is a nested struct.
+	Schema *Schema
+}
+
+// Type returns struct type that is built from the schema.
+func (s Schema) Type() (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(s.Fields))
+	names := make(map[string]bool)
+	for _, f := range s.Fields {
+		if !token.IsIdentifier(f.Name) || !token.IsExported(f.Name) {
+			return nil, fmt.Errorf("schema field name '%s' should be an exported identifier", f.Name)
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("schema field name '%s' is duplicated", f.Name)
+		}
+		names[f.Name] = true
+		t := f.Type
+		if f.Schema != nil {
+			var err error
+			t, err = f.Schema.Type()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if t == nil {
+			return nil, fmt.Errorf("schema field '%s' doesn't have type", f.Name)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: f.Name,
+			Type: t,
+			Tag:  f.Tag,
+		})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// New creates a new instance of the schema's struct type. It returns a pointer of struct
+// that can be passed to Decode() or Encode().
+func (s Schema) New() (any, error) {
+	t, err := s.Type()
+	if err != nil {
+		return nil, err
+	}
+	return reflect.New(t).Interface(), nil
+}
+
+// DecodeMap decodes into map[string]any by using the schema and the same Decoder implementation as Decode().
+//
+// Only fields that are set (or defaulted) are stored into dest. Nested schemas become nested map[string]any.
+func DecodeMap(dest map[string]any, schema *Schema, tags []string, decoder Decoder, opts ...Option) error {
+	instance, err := schema.New()
+	if err != nil {
+		return err
+	}
+	result, err := DecodeWithResult(instance, tags, decoder, opts...)
+	if result == nil {
+		return err
+	}
+	set := make(map[string]bool)
+	for _, p := range result.Set {
+		set[p] = true
+	}
+	for _, p := range result.Defaulted {
+		set[p] = true
+	}
+	schema.toMap(dest, reflect.ValueOf(instance).Elem(), "", set)
+	return err
+}
+
+func (s Schema) toMap(dest map[string]any, v reflect.Value, prefix string, set map[string]bool) {
+	for i, f := range s.Fields {
+		path := joinPath(prefix, f.Name)
+		fv := v.Field(i)
+		if set[path] {
+			dest[f.Name] = fv.Interface()
+		} else if f.Schema != nil {
+			child := make(map[string]any)
+			f.Schema.toMap(child, fv, path, set)
+			if len(child) > 0 {
+				dest[f.Name] = child
+			}
+		}
+	}
+}
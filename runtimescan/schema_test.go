@@ -0,0 +1,84 @@
+package runtimescan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "ID", Type: reflect.TypeOf(0), Tag: `map:"id"`},
+			{Name: "Name", Type: reflect.TypeOf(""), Tag: `map:"name"`},
+			{Name: "Missing", Type: reflect.TypeOf(""), Tag: `map:"missing"`},
+			{Name: "Address", Schema: &Schema{
+				Fields: []SchemaField{
+					{Name: "City", Type: reflect.TypeOf(""), Tag: `map:"city"`},
+				},
+			}},
+			{Name: "Empty", Schema: &Schema{
+				Fields: []SchemaField{
+					{Name: "Value", Type: reflect.TypeOf(""), Tag: `map:"value"`},
+				},
+			}},
+		},
+	}
+	d := mapDecoder{
+		values: map[string]any{
+			"id":   "10",
+			"name": "name",
+			"city": "Tokyo",
+		},
+	}
+
+	t.Run("same type", func(t *testing.T) {
+		t1, err := schema.Type()
+		assert.NoError(t, err)
+		t2, err := schema.Type()
+		assert.NoError(t, err)
+		assert.Equal(t, t1, t2)
+	})
+
+	t.Run("decode into struct", func(t *testing.T) {
+		instance, err := schema.New()
+		assert.NoError(t, err)
+		err = Decode(instance, []string{"map"}, &d)
+		assert.NoError(t, err)
+		id, err := Get(instance, "ID")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, id)
+		city, err := Get(instance, "Address.City")
+		assert.NoError(t, err)
+		assert.Equal(t, "Tokyo", city)
+	})
+
+	t.Run("decode into map", func(t *testing.T) {
+		dest := make(map[string]any)
+		err := DecodeMap(dest, schema, []string{"map"}, &d)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"ID":   10,
+			"Name": "name",
+			"Address": map[string]any{
+				"City": "Tokyo",
+			},
+		}, dest)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		tests := []Schema{
+			{Fields: []SchemaField{{Name: "lower", Type: reflect.TypeOf("")}}},
+			{Fields: []SchemaField{{Name: "Invalid Name", Type: reflect.TypeOf("")}}},
+			{Fields: []SchemaField{{Name: "NoType"}}},
+			{Fields: []SchemaField{{Name: "Dup", Type: reflect.TypeOf("")}, {Name: "Dup", Type: reflect.TypeOf("")}}},
+		}
+		for _, s := range tests {
+			_, err := s.New()
+			assert.Error(t, err)
+			err = DecodeMap(map[string]any{}, &s, []string{"map"}, &d)
+			assert.Error(t, err)
+		}
+	})
+}
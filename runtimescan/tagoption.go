@@ -0,0 +1,320 @@
+package runtimescan
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TagOption is a key/value pair of tag option like "omitempty" or "default=10".
+type TagOption struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// TagOptions is a result of ParseOptions().
+//
+// Name is the first element of the tag and Options keeps rest elements in written order.
+type TagOptions struct {
+	Name    string
+	Options []TagOption
+}
+
+// Has returns true if the tag has the option.
+func (t TagOptions) Has(key string) bool {
+	_, ok := t.Lookup(key)
+	return ok
+}
+
+// Lookup returns the first option that has the key.
+func (t TagOptions) Lookup(key string) (TagOption, bool) {
+	for _, o := range t.Options {
+		if o.Key == key {
+			return o, true
+		}
+	}
+	return TagOption{}, false
+}
+
+// Get returns the value of the option. It returns empty string if the tag doesn't have the option.
+func (t TagOptions) Get(key string) string {
+	o, _ := t.Lookup(key)
+	return o.Value
+}
+
+// Values returns all values of the option that appear several times like "enum=a,enum=b".
+func (t TagOptions) Values(key string) []string {
+	var result []string
+	for _, o := range t.Options {
+		if o.Key == key {
+			result = append(result, o.Value)
+		}
+	}
+	return result
+}
+
+// ParseOptions is a helper function that parses the standard tag option grammar.
+//
+// The first comma separated element is a name and the others are options. Each option
+// is "key" or "key=value":
+//
+//	name,omitempty,default=a\,b,min=3
+//
+// Backslash escapes the next character (\, \= \' \\). Single quoted value can contain
+// comma and equal sign as is (default='a,b'). A single quote starts quoting only at the beginning of
+// a name or a value, so it can be used as an apostrophe in other places (usage=don't print).
+func ParseOptions(tagStr string) (*TagOptions, error) {
+	result := &TagOptions{}
+	var buf strings.Builder
+	var key string
+	var hasKey bool
+	var inQuote bool
+	// quoted is true if the current name or value has been quoted
+	var quoted bool
+	first := true
+
+	flush := func() error {
+		if first {
+			result.Name = buf.String()
+			first = false
+		} else if hasKey {
+			if key == "" {
+				return fmt.Errorf("tag '%s' has an option without key: %w", tagStr, ErrParseTag)
+			}
+			result.Options = append(result.Options, TagOption{
+				Key:      key,
+				Value:    buf.String(),
+				HasValue: true,
+			})
+		} else {
+			k := strings.TrimSpace(buf.String())
+			if k == "" {
+				return fmt.Errorf("tag '%s' has an empty option: %w", tagStr, ErrParseTag)
+			}
+			result.Options = append(result.Options, TagOption{
+				Key: k,
+			})
+		}
+		buf.Reset()
+		key = ""
+		hasKey = false
+		quoted = false
+		return nil
+	}
+
+	runes := []rune(tagStr)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("tag '%s' ends with escape character: %w", tagStr, ErrParseTag)
+			}
+			i++
+			buf.WriteRune(runes[i])
+		case c == '\'' && inQuote:
+			inQuote = false
+		case c == '\'' && buf.Len() == 0 && !quoted:
+			inQuote = true
+			quoted = true
+		case inQuote:
+			buf.WriteRune(c)
+		case c == ',':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case c == '=' && !first && !hasKey:
+			key = strings.TrimSpace(buf.String())
+			hasKey = true
+			buf.Reset()
+			quoted = false
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("tag '%s' has unterminated quote: %w", tagStr, ErrParseTag)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// OptionType specifies the value type of tag option in OptionSchema.
+type OptionType int
+
+const (
+	// FlagOption is an option without value like "omitempty".
+	FlagOption OptionType = iota + 1
+	StringOption
+	IntOption
+	FloatOption
+	BoolOption
+)
+
+func (t OptionType) String() string {
+	switch t {
+	case FlagOption:
+		return "flag"
+	case StringOption:
+		return "string"
+	case IntOption:
+		return "int"
+	case FloatOption:
+		return "float"
+	case BoolOption:
+		return "bool"
+	}
+	return "unknown"
+}
+
+// OptionSpec is a definition of each option in OptionSchema.
+type OptionSpec struct {
+	Key  string
+	Type OptionType
+	// Conflicts is a list of option keys that can't be used with this option.
+	Conflicts []string
+	// Multiple allows to specify this option several times.
+	Multiple bool
+}
+
+// OptionSchema is a declarative definition of tag options.
+//
+// Parse() parses tag string by ParseOptions() and validates its options.
+type OptionSchema struct {
+	Options []OptionSpec
+	// RequireName makes an error if the tag doesn't have name.
+	RequireName bool
+}
+
+func (s OptionSchema) spec(key string) (OptionSpec, bool) {
+	for _, o := range s.Options {
+		if o.Key == key {
+			return o, true
+		}
+	}
+	return OptionSpec{}, false
+}
+
+// Parse parses tag string and validates it. pathStr is used in error messages.
+func (s OptionSchema) Parse(tagStr, pathStr string) (*TagOptions, error) {
+	result, err := ParseOptions(tagStr)
+	if err != nil {
+		return nil, err
+	}
+	err = s.Validate(result, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validate checks options of the tag.
+//
+// All returned errors wrap ErrParseTag.
+func (s OptionSchema) Validate(t *TagOptions, pathStr string) error {
+	if s.RequireName && t.Name == "" {
+		return fmt.Errorf("tag of field '%s' should have name: %w", pathStr, ErrParseTag)
+	}
+	found := make(map[string]bool)
+	for _, o := range t.Options {
+		spec, ok := s.spec(o.Key)
+		if !ok {
+			return fmt.Errorf("option '%s' of field '%s' is invalid. did you mean '%s'?: %w", o.Key, pathStr, s.nearest(o.Key), ErrParseTag)
+		}
+		if found[o.Key] && !spec.Multiple {
+			return fmt.Errorf("option '%s' of field '%s' is specified twice: %w", o.Key, pathStr, ErrParseTag)
+		}
+		found[o.Key] = true
+		if err := checkOptionValue(spec, o, pathStr); err != nil {
+			return err
+		}
+	}
+	for _, o := range t.Options {
+		spec, _ := s.spec(o.Key)
+		for _, c := range spec.Conflicts {
+			if found[c] {
+				return fmt.Errorf("option '%s' of field '%s' can't be used with '%s': %w", o.Key, pathStr, c, ErrParseTag)
+			}
+		}
+	}
+	return nil
+}
+
+func checkOptionValue(spec OptionSpec, o TagOption, pathStr string) error {
+	if spec.Type == FlagOption {
+		if o.HasValue {
+			return fmt.Errorf("option '%s' of field '%s' can't have value '%s': %w", o.Key, pathStr, o.Value, ErrParseTag)
+		}
+		return nil
+	}
+	if !o.HasValue {
+		return fmt.Errorf("option '%s' of field '%s' should have %s value: %w", o.Key, pathStr, spec.Type, ErrParseTag)
+	}
+	var err error
+	switch spec.Type {
+	case IntOption:
+		_, err = strconv.ParseInt(o.Value, 10, 64)
+	case FloatOption:
+		_, err = strconv.ParseFloat(o.Value, 64)
+	case BoolOption:
+		_, err = strconv.ParseBool(o.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("option '%s' of field '%s' should be %s, but '%s': %w", o.Key, pathStr, spec.Type, o.Value, ErrParseTag)
+	}
+	return nil
+}
+
+func (s OptionSchema) nearest(key string) string {
+	candidates := make([]string, 0, len(s.Options))
+	for _, o := range s.Options {
+		candidates = append(candidates, o.Key)
+	}
+	return nearest(key, candidates)
+}
+
+// nearest returns the candidate that has the smallest Levenshtein distance from the word.
+func nearest(word string, candidates []string) string {
+	var result string
+	dist := math.MaxInt
+	for _, c := range candidates {
+		d := levenshtein(word, c)
+		if d < dist {
+			dist = d
+			result = c
+		}
+	}
+	return result
+}
+
+func levenshtein(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
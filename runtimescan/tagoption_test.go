@@ -0,0 +1,198 @@
+package runtimescan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		tagStr  string
+		want    *TagOptions
+		wantErr bool
+	}{
+		{
+			name:   "name only",
+			tagStr: "name",
+			want: &TagOptions{
+				Name: "name",
+			},
+		},
+		{
+			name:   "empty",
+			tagStr: "",
+			want:   &TagOptions{},
+		},
+		{
+			name:   "flag and values",
+			tagStr: `name,omitempty,default=a\,b,min=3`,
+			want: &TagOptions{
+				Name: "name",
+				Options: []TagOption{
+					{Key: "omitempty"},
+					{Key: "default", Value: "a,b", HasValue: true},
+					{Key: "min", Value: "3", HasValue: true},
+				},
+			},
+		},
+		{
+			name:   "without name",
+			tagStr: ",omitempty",
+			want: &TagOptions{
+				Options: []TagOption{
+					{Key: "omitempty"},
+				},
+			},
+		},
+		{
+			name:   "quoted value",
+			tagStr: `name,default='a,b=c',sep='\''`,
+			want: &TagOptions{
+				Name: "name",
+				Options: []TagOption{
+					{Key: "default", Value: "a,b=c", HasValue: true},
+					{Key: "sep", Value: "'", HasValue: true},
+				},
+			},
+		},
+		{
+			name:   "apostrophe in value",
+			tagStr: `v,usage=don't print,default='a,b'c's`,
+			want: &TagOptions{
+				Name: "v",
+				Options: []TagOption{
+					{Key: "usage", Value: "don't print", HasValue: true},
+					{Key: "default", Value: "a,bc's", HasValue: true},
+				},
+			},
+		},
+		{
+			name:   "equal in value and empty value",
+			tagStr: `name,expr=a=b,default=`,
+			want: &TagOptions{
+				Name: "name",
+				Options: []TagOption{
+					{Key: "expr", Value: "a=b", HasValue: true},
+					{Key: "default", Value: "", HasValue: true},
+				},
+			},
+		},
+		{
+			name:    "unterminated quote",
+			tagStr:  `name,default='a,b`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing escape",
+			tagStr:  `name,default=a\`,
+			wantErr: true,
+		},
+		{
+			name:    "empty option",
+			tagStr:  `name,,omitempty`,
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			tagStr:  `name,=value`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptions(tt.tagStr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, ErrParseTag))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTagOptions_Accessors(t *testing.T) {
+	o, err := ParseOptions("name,enum=a,omitempty,enum=b")
+	assert.NoError(t, err)
+	assert.True(t, o.Has("omitempty"))
+	assert.False(t, o.Has("required"))
+	assert.Equal(t, "a", o.Get("enum"))
+	assert.Equal(t, "", o.Get("required"))
+	assert.Equal(t, []string{"a", "b"}, o.Values("enum"))
+}
+
+func TestOptionSchema_Parse(t *testing.T) {
+	schema := OptionSchema{
+		Options: []OptionSpec{
+			{Key: "omitempty", Type: FlagOption},
+			{Key: "required", Type: FlagOption, Conflicts: []string{"default"}},
+			{Key: "default", Type: StringOption},
+			{Key: "min", Type: IntOption},
+			{Key: "ratio", Type: FloatOption},
+			{Key: "secret", Type: BoolOption},
+			{Key: "enum", Type: StringOption, Multiple: true},
+		},
+		RequireName: true,
+	}
+	tests := []struct {
+		name    string
+		tagStr  string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			tagStr: "name,omitempty,default=x,min=-3,ratio=0.5,secret=true,enum=a,enum=b",
+		},
+		{
+			name:    "unknown option",
+			tagStr:  "name,omitempy",
+			wantErr: "option 'omitempy' of field 'Field' is invalid. did you mean 'omitempty'?: tag parse error",
+		},
+		{
+			name:    "conflict",
+			tagStr:  "name,required,default=x",
+			wantErr: "option 'required' of field 'Field' can't be used with 'default': tag parse error",
+		},
+		{
+			name:    "twice",
+			tagStr:  "name,min=1,min=2",
+			wantErr: "option 'min' of field 'Field' is specified twice: tag parse error",
+		},
+		{
+			name:    "flag with value",
+			tagStr:  "name,omitempty=true",
+			wantErr: "option 'omitempty' of field 'Field' can't have value 'true': tag parse error",
+		},
+		{
+			name:    "value is missing",
+			tagStr:  "name,min",
+			wantErr: "option 'min' of field 'Field' should have int value: tag parse error",
+		},
+		{
+			name:    "invalid int",
+			tagStr:  "name,min=three",
+			wantErr: "option 'min' of field 'Field' should be int, but 'three': tag parse error",
+		},
+		{
+			name:    "name is required",
+			tagStr:  ",omitempty",
+			wantErr: "tag of field 'Field' should have name: tag parse error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Parse(tt.tagStr, "Field")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.True(t, errors.Is(err, ErrParseTag))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "name", got.Name)
+			}
+		})
+	}
+}
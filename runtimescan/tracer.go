@@ -0,0 +1,166 @@
+package runtimescan
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// TraceOp is an operation that emits TraceEvent.
+type TraceOp string
+
+const (
+	TraceDecode TraceOp = "decode"
+	TraceEncode TraceOp = "encode"
+)
+
+// TraceStatus is a result of each field.
+type TraceStatus string
+
+const (
+	// TraceSet means Decode() assigned the value to the field.
+	TraceSet TraceStatus = "set"
+	// TraceDefaulted means Decode() assigned the DefaultValue to the field.
+	TraceDefaulted TraceStatus = "defaulted"
+	// TraceVisited means Encode() passed the value to VisitField().
+	TraceVisited TraceStatus = "visited"
+	TraceSkipped TraceStatus = "skipped"
+	TraceFailed  TraceStatus = "failed"
+)
+
+// TraceEvent is a structured event of each field that Decode() and Encode() process.
+type TraceEvent struct {
+	Op   TraceOp
+	Path string
+	// Tag is a value that ParseTag() returned.
+	Tag any
+	// Value is a value that ExtractValue() returned or a value that is passed to VisitField().
+	Value  any
+	Status TraceStatus
+	// SkipReason describes why the field is skipped, like which method returned Skip.
+	SkipReason string
+	// Conversion describes how the value is converted, like "string -> int".
+	Conversion string
+	Err        error
+	Elapsed    time.Duration
+}
+
+// Tracer receives TraceEvent of each field. Pass it to Decode() and Encode() by WithTracer() option.
+//
+// It helps to find why a field is not populated without adding prints to Decoder.
+type Tracer interface {
+	TraceField(e *TraceEvent)
+}
+
+// TracerFunc is an adapter to use ordinary function as Tracer.
+type TracerFunc func(e *TraceEvent)
+
+// TraceField calls f(e).
+func (f TracerFunc) TraceField(e *TraceEvent) {
+	f(e)
+}
+
+// WithTracer sets Tracer.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+func (k resultKind) traceStatus() TraceStatus {
+	switch k {
+	case resultSet:
+		return TraceSet
+	case resultSkipped:
+		return TraceSkipped
+	case resultDefaulted:
+		return TraceDefaulted
+	}
+	return TraceFailed
+}
+
+// traceSkipped reports fields that are dropped when compiling like ParseTag() returns Skip or masks exclude them.
+func traceSkipped(t Tracer, op TraceOp, v *parser, prefix string) {
+	for _, f := range v.skipped {
+		t.TraceField(&TraceEvent{
+			Op:         op,
+			Path:       joinPath(prefix, f.path),
+			Tag:        f.tag,
+			Status:     TraceSkipped,
+			SkipReason: f.reason,
+		})
+	}
+}
+
+// conversion describes how FuzzyAssign() converts the value into the field.
+func conversion(value any, f *field, t reflect.Type) string {
+	if f.decodable {
+		return "DecodeTag"
+	}
+	if value == nil {
+		return ""
+	}
+	vt := reflect.TypeOf(value)
+	if vt == t || (f.isPtr && vt == f.eType) {
+		return ""
+	}
+	return fmt.Sprintf("%s -> %s", vt, t)
+}
+
+// TableTracer is a Tracer that writes events as a readable text table.
+//
+// Events are buffered until Flush() is called.
+type TableTracer struct {
+	lock   sync.Mutex
+	w      io.Writer
+	events []*TraceEvent
+}
+
+// NewTableTracer creates TableTracer that writes to w.
+func NewTableTracer(w io.Writer) *TableTracer {
+	return &TableTracer{w: w}
+}
+
+// TraceField implements Tracer.
+func (t *TableTracer) TraceField(e *TraceEvent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.events = append(t.events, e)
+}
+
+// Flush writes buffered events as a table and clears them.
+func (t *TableTracer) Flush() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OP\tPATH\tTAG\tVALUE\tSTATUS\tDETAIL\tELAPSED")
+	for _, e := range t.events {
+		var tag, value string
+		if e.Tag != nil {
+			tag = fmt.Sprintf("%+v", e.Tag)
+		}
+		if e.Value != nil {
+			value = fmt.Sprintf("%#v", e.Value)
+		}
+		var detail []string
+		if e.Conversion != "" {
+			detail = append(detail, e.Conversion)
+		}
+		if e.SkipReason != "" {
+			detail = append(detail, e.SkipReason)
+		}
+		if e.Err != nil {
+			detail = append(detail, e.Err.Error())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Op, e.Path, tag, value, e.Status, strings.Join(detail, "; "), e.Elapsed)
+	}
+	w.Flush()
+	t.events = nil
+	_, err := io.WriteString(t.w, trimTable(b.String()))
+	return err
+}
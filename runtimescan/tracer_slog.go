@@ -0,0 +1,52 @@
+//go:build go1.21
+
+package runtimescan
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogTracer is a Tracer that emits a log/slog record per field.
+type SlogTracer struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewSlogTracer creates SlogTracer. Failed fields are logged at slog.LevelWarn if level is lower than it.
+func NewSlogTracer(logger *slog.Logger, level slog.Level) *SlogTracer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogTracer{logger: logger, level: level}
+}
+
+// TraceField implements Tracer.
+func (t *SlogTracer) TraceField(e *TraceEvent) {
+	level := t.level
+	if e.Err != nil && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	ctx := context.Background()
+	if !t.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("op", string(e.Op)),
+		slog.String("path", e.Path),
+		slog.Any("tag", e.Tag),
+		slog.Any("value", e.Value),
+		slog.String("status", string(e.Status)),
+		slog.Duration("elapsed", e.Elapsed),
+	}
+	if e.Conversion != "" {
+		attrs = append(attrs, slog.String("conversion", e.Conversion))
+	}
+	if e.SkipReason != "" {
+		attrs = append(attrs, slog.String("skipReason", e.SkipReason))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.Any("error", e.Err))
+	}
+	t.logger.LogAttrs(ctx, level, "runtimescan field", attrs...)
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+
+package runtimescan
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogTracer(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "elapsed" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	d := &mapDecoder{values: map[string]any{"name": "alice", "age": "x"}}
+	var target traceTarget
+	err := Decode(&target, []string{"map"}, d, Include("Name", "Age"), WithTracer(NewSlogTracer(logger, slog.LevelInfo)))
+	assert.Error(t, err)
+	assert.Equal(t, `level=INFO msg="runtimescan field" op=decode path=Missing tag=missing value=<nil> status=skipped skipReason="excluded by mask"
+level=INFO msg="runtimescan field" op=decode path=Broken tag=broken value=<nil> status=skipped skipReason="excluded by mask"
+level=INFO msg="runtimescan field" op=decode path=Role tag=role value=<nil> status=skipped skipReason="excluded by mask"
+level=INFO msg="runtimescan field" op=decode path=Name tag=name value=alice status=set
+level=WARN msg="runtimescan field" op=decode path=Age tag=age value=x status=failed conversion="string -> int" error="strconv.ParseInt: parsing \"x\": invalid syntax"
+`, b.String())
+}
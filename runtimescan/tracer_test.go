@@ -0,0 +1,158 @@
+package runtimescan
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type traceTarget struct {
+	Name    string `map:"name"`
+	Age     int    `map:"age"`
+	Missing string `map:"missing"`
+	Broken  int    `map:"broken"`
+	Role    string `map:"role"`
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		var events []*TraceEvent
+		d := &defaultDecoder{
+			mapDecoder: mapDecoder{values: map[string]any{"name": "alice", "age": "20", "broken": "x"}},
+			defaults:   map[string]any{"role": "user"},
+		}
+		var target traceTarget
+		err := Decode(&target, []string{"map"}, d, WithTracer(TracerFunc(func(e *TraceEvent) {
+			events = append(events, e)
+		})))
+		assert.Error(t, err)
+		assert.Len(t, events, 5)
+		assert.Equal(t, TraceDecode, events[0].Op)
+		assert.Equal(t, TraceSet, events[0].Status)
+		assert.Equal(t, "alice", events[0].Value)
+		assert.Equal(t, "", events[0].Conversion)
+		assert.Equal(t, "string -> int", events[1].Conversion)
+		assert.Equal(t, TraceSkipped, events[2].Status)
+		assert.Equal(t, "ExtractValue returned Skip", events[2].SkipReason)
+		assert.Equal(t, TraceFailed, events[3].Status)
+		assert.Error(t, events[3].Err)
+		assert.Equal(t, TraceDefaulted, events[4].Status)
+		assert.Equal(t, "user", events[4].Value)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		var events []*TraceEvent
+		m := mapEncoder{result: map[string]any{}}
+		source := traceTarget{Name: "alice"}
+		err := Encode(&source, []string{"map"}, &m, WithTracer(TracerFunc(func(e *TraceEvent) {
+			events = append(events, e)
+		})))
+		assert.NoError(t, err)
+		assert.Len(t, events, 5)
+		assert.Equal(t, TraceEncode, events[0].Op)
+		assert.Equal(t, "Name", events[0].Path)
+		assert.Equal(t, TraceVisited, events[0].Status)
+		assert.Equal(t, "alice", events[0].Value)
+	})
+
+	t.Run("nil embedded pointer", func(t *testing.T) {
+		type base struct {
+			ID string `map:"id"`
+		}
+		type target struct {
+			*base
+			Name string `map:"name"`
+		}
+		var events []*TraceEvent
+		m := mapEncoder{result: map[string]any{}}
+		err := Encode(&target{Name: "alice"}, []string{"map"}, &m, WithTracer(TracerFunc(func(e *TraceEvent) {
+			events = append(events, e)
+		})))
+		assert.NoError(t, err)
+		if assert.Len(t, events, 2) {
+			assert.Equal(t, "base.ID", events[0].Path)
+			assert.Equal(t, TraceSkipped, events[0].Status)
+			assert.Equal(t, "in nil embedded pointer", events[0].SkipReason)
+			assert.Equal(t, TraceVisited, events[1].Status)
+		}
+		assert.Equal(t, map[string]any{"name": "alice"}, m.result)
+	})
+
+	t.Run("fields dropped when compiling", func(t *testing.T) {
+		var events []*TraceEvent
+		d := &skipDecoder{mapDecoder: mapDecoder{values: map[string]any{"name": "alice"}}}
+		var target struct {
+			Name   string `map:"name"`
+			Secret string
+			Role   string `map:"role"`
+		}
+		err := Decode(&target, []string{"map"}, d, Exclude("Role"), WithTracer(TracerFunc(func(e *TraceEvent) {
+			events = append(events, e)
+		})))
+		assert.NoError(t, err)
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, "Secret", events[0].Path)
+			assert.Equal(t, TraceSkipped, events[0].Status)
+			assert.Equal(t, "ParseTag returned Skip", events[0].SkipReason)
+			assert.Equal(t, "Role", events[1].Path)
+			assert.Equal(t, "excluded by mask", events[1].SkipReason)
+			assert.Equal(t, "Name", events[2].Path)
+			assert.Equal(t, TraceSet, events[2].Status)
+		}
+	})
+
+	t.Run("polymorphic", func(t *testing.T) {
+		var events []*TraceEvent
+		tracer := TracerFunc(func(e *TraceEvent) {
+			events = append(events, e)
+		})
+		d := mapDecoder{values: map[string]any{"id": "1", "type": "user.created", "user_id": "u1"}}
+		e := event{}
+		err := Decode(&e, []string{"map"}, &d, WithTypeRegistry(newEventRegistry()), WithTracer(tracer))
+		assert.NoError(t, err)
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, "Payload.UserID", events[1].Path)
+			assert.Equal(t, "Payload", events[2].Path)
+			assert.Equal(t, TraceSet, events[2].Status)
+			assert.Equal(t, "user.created", events[2].Value)
+			assert.Equal(t, "user.created -> runtimescan.userCreated", events[2].Conversion)
+		}
+
+		events = nil
+		m := mapEncoder{result: map[string]any{}}
+		err = Encode(&e, []string{"map"}, &m, WithTypeRegistry(newEventRegistry()), WithTracer(tracer))
+		assert.NoError(t, err)
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, "Payload.UserID", events[1].Path)
+			assert.Equal(t, "Payload", events[2].Path)
+			assert.Equal(t, TraceVisited, events[2].Status)
+			assert.Equal(t, "user.created", events[2].Value)
+		}
+	})
+}
+
+type skipDecoder struct {
+	mapDecoder
+}
+
+func (d skipDecoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	if tagStr == "" {
+		return nil, Skip
+	}
+	return tagStr, nil
+}
+
+func TestTableTracer(t *testing.T) {
+	var b strings.Builder
+	tracer := NewTableTracer(&b)
+	tracer.TraceField(&TraceEvent{Op: TraceDecode, Path: "Name", Tag: "name", Value: "alice", Status: TraceSet})
+	tracer.TraceField(&TraceEvent{Op: TraceDecode, Path: "Age", Tag: "age", Value: "x", Status: TraceFailed, Conversion: "string -> int", Err: errors.New("invalid syntax")})
+	assert.NoError(t, tracer.Flush())
+	assert.Equal(t, `OP      PATH  TAG   VALUE    STATUS  DETAIL                         ELAPSED
+decode  Name  name  "alice"  set                                    0s
+decode  Age   age   "x"      failed  string -> int; invalid syntax  0s
+`, b.String())
+}
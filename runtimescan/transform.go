@@ -0,0 +1,70 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// Transformer is an interface that rewrites struct fields in place by using Transform().
+//
+// ParseTag() is used when parsing struct tag.
+type Transformer interface {
+	Parser
+	// TransformField is called for each field. It receives the current value and returns a replacement.
+	// If it returns Skip, the field is kept as is. If the field is a pointer, value is a dereferenced
+	// value or nil and returning nil sets nil to the field.
+	TransformField(tag, value any) (newValue any, err error)
+}
+
+// Transform rewrites fields of the struct that ptr points by using tag information.
+//
+// It enables tag driven trimming, redaction, encryption-at-rest or normalization in one pass
+// without the round trip of Encode() and Decode().
+func Transform(ptr any, tags []string, transformer Transformer, opts ...Option) error {
+	o := newOptions(opts)
+	v, err := getProgram(ptr, tags, transformer, o, true)
+	if err != nil {
+		return err
+	}
+	root := reflect.ValueOf(ptr).Elem()
+	var errors []error
+	for i, op := range v.fieldOps {
+		if op != visitFieldOp {
+			continue
+		}
+		field := v.fields[i]
+		fv, _ := field.get(root, false)
+		if !fv.IsValid() {
+			// in nil embedded pointer
+			continue
+		}
+		var value any
+		if field.isPtr {
+			if !fv.IsNil() {
+				value = fv.Elem().Interface()
+			}
+		} else {
+			value = fv.Interface()
+		}
+		newValue, err := transformer.TransformField(field.tag, value)
+		if err == Skip {
+			continue
+		} else if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+		if newValue == nil {
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		err = FuzzyAssign(fv, newValue)
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) > 0 {
+		return &Errors{
+			Errors: errors,
+		}
+	}
+	return nil
+}
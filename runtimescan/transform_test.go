@@ -0,0 +1,90 @@
+package runtimescan
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sanitizer struct{}
+
+func (s sanitizer) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	if tagStr == "" {
+		return nil, Skip
+	}
+	return tagStr, nil
+}
+
+func (s sanitizer) TransformField(tag, value any) (any, error) {
+	switch tag.(string) {
+	case "trim":
+		return strings.TrimSpace(value.(string)), nil
+	case "redact":
+		if value == nil {
+			return nil, Skip
+		}
+		return "***", nil
+	case "clear":
+		return nil, nil
+	case "error":
+		return nil, errors.New("transform error")
+	}
+	return nil, Skip
+}
+
+func TestTransform(t *testing.T) {
+	type Account struct {
+		Password string `sanitize:"redact"`
+	}
+	type User struct {
+		Name    string  `sanitize:"trim"`
+		Token   *string `sanitize:"redact"`
+		Empty   *string `sanitize:"redact"`
+		Age     int     `sanitize:"clear"`
+		Memo    *string `sanitize:"clear"`
+		Keep    string  `sanitize:"keep"`
+		Account Account
+	}
+
+	t.Run("transform", func(t *testing.T) {
+		u := User{
+			Name:    "  user  ",
+			Token:   &[]string{"token"}[0],
+			Age:     20,
+			Memo:    &[]string{"memo"}[0],
+			Keep:    " keep ",
+			Account: Account{Password: "secret"},
+		}
+		err := Transform(&u, []string{"sanitize"}, &sanitizer{})
+		assert.NoError(t, err)
+		assert.Equal(t, "user", u.Name)
+		assert.Equal(t, "***", *u.Token)
+		assert.Nil(t, u.Empty)
+		assert.Equal(t, 0, u.Age)
+		assert.Nil(t, u.Memo)
+		assert.Equal(t, " keep ", u.Keep)
+		assert.Equal(t, "***", u.Account.Password)
+	})
+
+	t.Run("with mask", func(t *testing.T) {
+		u := User{
+			Name:    "  user  ",
+			Account: Account{Password: "secret"},
+		}
+		err := Transform(&u, []string{"sanitize"}, &sanitizer{}, Exclude("Account"))
+		assert.NoError(t, err)
+		assert.Equal(t, "user", u.Name)
+		assert.Equal(t, "secret", u.Account.Password)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		type Invalid struct {
+			Name string `sanitize:"error"`
+		}
+		err := Transform(&Invalid{}, []string{"sanitize"}, &sanitizer{})
+		assert.EqualError(t, err, "1 errors: \n* transform error")
+	})
+}
@@ -0,0 +1,137 @@
+package runtimescan
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedDecoder is an optional interface of Decoder for hot decoders.
+//
+// If the decoder implements it, Decode() calls the typed method that is chosen when parsing
+// struct from the field type instead of ExtractValue(), and writes the value directly to the field.
+// It avoids boxing values into any and FuzzyAssign() conversion.
+// Fields of other types (and TagDecodable fields) still use ExtractValue().
+//
+// Each method can return Skip as same as ExtractValue(). ExtractInt64() is also used for unsigned
+// integer fields.
+type TypedDecoder interface {
+	Decoder
+	ExtractString(tag any) (string, error)
+	ExtractInt64(tag any) (int64, error)
+	ExtractFloat64(tag any) (float64, error)
+	ExtractBool(tag any) (bool, error)
+	ExtractBytes(tag any) ([]byte, error)
+}
+
+type typedKind int
+
+const (
+	untyped typedKind = iota
+	typedString
+	typedInt
+	typedUint
+	typedFloat
+	typedBool
+	typedBytes
+)
+
+// method returns the name of TypedDecoder's method.
+func (k typedKind) method() string {
+	switch k {
+	case typedString:
+		return "ExtractString"
+	case typedInt, typedUint:
+		return "ExtractInt64"
+	case typedFloat:
+		return "ExtractFloat64"
+	case typedBool:
+		return "ExtractBool"
+	case typedBytes:
+		return "ExtractBytes"
+	}
+	return "ExtractValue"
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+func detectTypedKind(t reflect.Type) typedKind {
+	switch t.Kind() {
+	case reflect.String:
+		return typedString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return typedInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typedUint
+	case reflect.Float32, reflect.Float64:
+		return typedFloat
+	case reflect.Bool:
+		return typedBool
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && t.ConvertibleTo(bytesType) {
+			return typedBytes
+		}
+	}
+	return untyped
+}
+
+// decodeTyped extracts the value by the typed method and writes it to the field.
+func decodeTyped(d TypedDecoder, fv reflect.Value, f *field) error {
+	switch f.typed {
+	case typedString:
+		v, err := d.ExtractString(f.tag)
+		if err != nil {
+			return err
+		}
+		typedTarget(fv, f).SetString(v)
+	case typedInt:
+		v, err := d.ExtractInt64(f.tag)
+		if err != nil {
+			return err
+		}
+		t := typedTarget(fv, f)
+		if t.OverflowInt(v) {
+			return fmt.Errorf("value %d overflows field '%s' (%s): %w", v, f.path, f.eType, ErrAssignError)
+		}
+		t.SetInt(v)
+	case typedUint:
+		v, err := d.ExtractInt64(f.tag)
+		if err != nil {
+			return err
+		}
+		t := typedTarget(fv, f)
+		if v < 0 || t.OverflowUint(uint64(v)) {
+			return fmt.Errorf("value %d overflows field '%s' (%s): %w", v, f.path, f.eType, ErrAssignError)
+		}
+		t.SetUint(uint64(v))
+	case typedFloat:
+		v, err := d.ExtractFloat64(f.tag)
+		if err != nil {
+			return err
+		}
+		typedTarget(fv, f).SetFloat(v)
+	case typedBool:
+		v, err := d.ExtractBool(f.tag)
+		if err != nil {
+			return err
+		}
+		typedTarget(fv, f).SetBool(v)
+	case typedBytes:
+		v, err := d.ExtractBytes(f.tag)
+		if err != nil {
+			return err
+		}
+		typedTarget(fv, f).SetBytes(v)
+	}
+	return nil
+}
+
+// typedTarget returns the value to write. Nil pointer fields are allocated.
+func typedTarget(fv reflect.Value, f *field) reflect.Value {
+	if !f.isPtr {
+		return fv
+	}
+	if fv.IsNil() {
+		fv.Set(reflect.New(f.eType))
+	}
+	return fv.Elem()
+}
@@ -0,0 +1,149 @@
+package runtimescan
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logTag struct {
+	index int
+}
+
+// logDecoder decodes space separated log line fields.
+type logDecoder struct {
+	fields []string
+}
+
+func (d *logDecoder) ParseTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (any, error) {
+	i, err := strconv.Atoi(tagStr)
+	if err != nil {
+		return nil, Skip
+	}
+	return &logTag{index: i}, nil
+}
+
+func (d *logDecoder) field(tag any) (string, error) {
+	i := tag.(*logTag).index
+	if i >= len(d.fields) {
+		return "", Skip
+	}
+	return d.fields[i], nil
+}
+
+func (d *logDecoder) ExtractValue(tag any) (any, error) {
+	return d.field(tag)
+}
+
+type typedLogDecoder struct {
+	logDecoder
+}
+
+func (d *typedLogDecoder) ExtractString(tag any) (string, error) {
+	return d.field(tag)
+}
+
+func (d *typedLogDecoder) ExtractInt64(tag any) (int64, error) {
+	s, err := d.field(tag)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func (d *typedLogDecoder) ExtractFloat64(tag any) (float64, error) {
+	s, err := d.field(tag)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func (d *typedLogDecoder) ExtractBool(tag any) (bool, error) {
+	s, err := d.field(tag)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
+
+func (d *typedLogDecoder) ExtractBytes(tag any) ([]byte, error) {
+	s, err := d.field(tag)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+type logLine struct {
+	Host    string   `log:"0"`
+	Status  int      `log:"1"`
+	Size    uint32   `log:"2"`
+	Elapsed float64  `log:"3"`
+	Cached  bool     `log:"4"`
+	Body    []byte   `log:"5"`
+	Retry   *int8    `log:"6"`
+	Missing string   `log:"7"`
+	Labels  []string `log:"-"`
+}
+
+func TestTypedDecoder(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		d := &typedLogDecoder{logDecoder{fields: []string{"example.com", "200", "1024", "0.25", "true", "body", "3"}}}
+		l := logLine{}
+		result, err := DecodeWithResult(&l, []string{"log"}, d)
+		assert.NoError(t, err)
+		assert.Equal(t, logLine{
+			Host:    "example.com",
+			Status:  200,
+			Size:    1024,
+			Elapsed: 0.25,
+			Cached:  true,
+			Body:    []byte("body"),
+			Retry:   &[]int8{3}[0],
+		}, l)
+		assert.Equal(t, []string{"Missing"}, result.Skipped)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		d := &typedLogDecoder{logDecoder{fields: []string{"example.com", "200", "-1", "0", "false", "", "300"}}}
+		l := logLine{}
+		result, err := DecodeWithResult(&l, []string{"log"}, d)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"Size", "Retry"}, result.Failed)
+	})
+}
+
+var benchmarkLine = []string{"example.com", "200", "1024", "0.25", "true", "body", "3"}
+
+type benchmarkLogLine struct {
+	Host    string  `log:"0"`
+	Status  int     `log:"1"`
+	Size    uint32  `log:"2"`
+	Elapsed float64 `log:"3"`
+	Cached  bool    `log:"4"`
+}
+
+func BenchmarkDecode_ExtractValue(b *testing.B) {
+	d := &logDecoder{fields: benchmarkLine}
+	var l benchmarkLogLine
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Decode(&l, []string{"log"}, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode_TypedDecoder(b *testing.B) {
+	d := &typedLogDecoder{logDecoder{fields: benchmarkLine}}
+	var l benchmarkLogLine
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Decode(&l, []string{"log"}, d); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
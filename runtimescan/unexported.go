@@ -0,0 +1,46 @@
+package runtimescan
+
+import (
+	"reflect"
+)
+
+// UnexportedParser is an optional interface of Parser.
+//
+// When unexported fields are traversed by IncludeUnexported() option, ParseUnexportedTag() is called for them
+// (and fields of unexported child structs) instead of ParseTag(). The parameters are as same as ParseTag().
+type UnexportedParser interface {
+	ParseUnexportedTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (tag any, err error)
+}
+
+// IncludeUnexported makes Encode() and EncodeAll() visit unexported fields too. They are only read.
+//
+// It is useful to dump domain structs that keep state private for debugging or audit logs.
+// Values of unexported fields are deeply copied without package unsafe, so encoders can't modify the source.
+// Fields that have func, chan, unsafe.Pointer or structs with unexported fields (like time.Time) can't be copied
+// and are skipped unless UnsafeAssignUnexported() is passed. Hooks of unexported child structs are not called.
+// Decode() and other functions that assign fields still skip unexported fields unless UnsafeAssignUnexported() is passed.
+func IncludeUnexported() Option {
+	return func(o *options) {
+		o.unexported = true
+	}
+}
+
+// UnsafeAssignUnexported makes Decode(), Transform() and other functions that assign fields
+// write unexported fields too. It bypasses Go's visibility by package unsafe.
+// Encode() also reads unexported fields as is instead of copying them.
+//
+// It implies IncludeUnexported().
+func UnsafeAssignUnexported() Option {
+	return func(o *options) {
+		o.unexported = true
+		o.unsafeAssign = true
+	}
+}
+
+// traverseUnexported returns true if the compiled parser should have unexported fields.
+func (o *options) traverseUnexported(write bool) bool {
+	if write {
+		return o.unsafeAssign
+	}
+	return o.unexported
+}
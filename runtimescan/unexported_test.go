@@ -0,0 +1,113 @@
+package runtimescan
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	ID      string `map:"id"`
+	balance int    `map:"balance"`
+	owner   struct {
+		Name string `map:"name"`
+	}
+}
+
+type unexportedEncoder struct {
+	mapEncoder
+	unexported []string
+}
+
+func (m *unexportedEncoder) ParseUnexportedTag(name, tagKey, tagStr, pathStr string, elemType reflect.Type) (tag any, err error) {
+	m.unexported = append(m.unexported, pathStr)
+	return tagStr, nil
+}
+
+func TestUnexported(t *testing.T) {
+	t.Run("encode", func(t *testing.T) {
+		source := account{ID: "a1", balance: 100}
+		source.owner.Name = "alice"
+
+		m := mapEncoder{result: map[string]any{}}
+		err := Encode(&source, []string{"map"}, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "a1"}, m.result)
+
+		m = mapEncoder{result: map[string]any{}}
+		err = Encode(&source, []string{"map"}, &m, IncludeUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "a1", "balance": 100, "name": "alice"}, m.result)
+	})
+
+	t.Run("parser is told", func(t *testing.T) {
+		source := account{ID: "a1"}
+		m := &unexportedEncoder{mapEncoder: mapEncoder{result: map[string]any{}}}
+		err := Encode(&source, []string{"map"}, m, IncludeUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"balance", "owner", "owner.Name"}, m.unexported)
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		d := &mapDecoder{values: map[string]any{"id": "a1", "balance": "100", "name": "alice"}}
+
+		var target account
+		err := Decode(&target, []string{"map"}, d, IncludeUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, account{ID: "a1"}, target)
+
+		err = Decode(&target, []string{"map"}, d, UnsafeAssignUnexported())
+		assert.NoError(t, err)
+		assert.Equal(t, 100, target.balance)
+		assert.Equal(t, "alice", target.owner.Name)
+	})
+	t.Run("encode copies values", func(t *testing.T) {
+		type node struct {
+			Name string
+			Next *node
+		}
+		type source struct {
+			limit   *int
+			labels  map[string]string
+			tags    []string
+			node    *node
+			created time.Time
+			fn      func()
+		}
+		jst := time.FixedZone("JST", 9*60*60)
+		limit := 10
+		n := &node{Name: "a"}
+		n.Next = n
+		src := source{
+			limit:   &limit,
+			labels:  map[string]string{"k": "v"},
+			tags:    []string{"a"},
+			node:    n,
+			created: time.Date(2020, 1, 2, 3, 4, 5, 0, jst),
+		}
+		e := &fieldValueEncoder{values: map[string]FieldValue{}}
+		err := Encode(&src, []string{"map"}, e, IncludeUnexported())
+		assert.NoError(t, err)
+		*e.values["limit"].Original.(*int) = 999
+		e.values["labels"].Value.(map[string]string)["k"] = "changed"
+		e.values["tags"].Value.([]string)[0] = "changed"
+		copied := e.values["node"].Value.(node)
+		assert.Equal(t, "a", copied.Next.Name)
+		assert.Same(t, copied.Next, copied.Next.Next)
+
+		assert.Equal(t, 10, limit)
+		assert.Equal(t, "v", src.labels["k"])
+		assert.Equal(t, "a", src.tags[0])
+		_, ok := e.values["created.loc"]
+		assert.False(t, ok, "time.Location has unexported fields")
+		_, ok = e.values["fn"]
+		assert.False(t, ok, "func can't be copied")
+
+		e = &fieldValueEncoder{values: map[string]FieldValue{}}
+		err = Encode(&src, []string{"map"}, e, UnsafeAssignUnexported())
+		assert.NoError(t, err)
+		assert.Same(t, jst, e.values["created.loc"].Original)
+	})
+}